@@ -7,31 +7,15 @@ import (
 	"io"
 	"math"
 	"strconv"
+	"strings"
 
-	cbor "github.com/ipfs/go-ipld-cbor"
 	"github.com/minio/blake2b-simd"
 	"github.com/multiformats/go-varint"
-	"github.com/polydawn/refmt/obj/atlas"
 	"golang.org/x/xerrors"
 
 	cbg "github.com/whyrusleeping/cbor-gen"
 )
 
-func init() {
-	cbor.RegisterCborType(addressAtlasEntry)
-}
-
-var addressAtlasEntry = atlas.BuildEntry(Address{}).Transform().
-	TransformMarshal(atlas.MakeMarshalTransformFunc(
-		func(a Address) (string, error) {
-			return string(a.Bytes()), nil
-		})).
-	TransformUnmarshal(atlas.MakeUnmarshalTransformFunc(
-		func(x string) (Address, error) {
-			return NewFromBytes([]byte(x))
-		})).
-	Complete()
-
 // CurrentNetwork specifies which network the address belongs to
 var CurrentNetwork = Testnet
 
@@ -110,14 +94,41 @@ func (a Address) Empty() bool {
 	return a == Undef
 }
 
-// Unmarshal unmarshals the cbor bytes into the address.
-func (a Address) Unmarshal(b []byte) error {
-	return cbor.DecodeInto(b, &a)
+// Unmarshal unmarshals the cbor bytes produced by Marshal into the address.
+// It also accepts the encoding of MarshalCBOR.
+func (a *Address) Unmarshal(b []byte) error {
+	br := bytes.NewReader(b)
+	if len(b) > 0 && b[0]>>5 == cbg.MajTextString {
+		_, extra, err := cbg.CborReadHeader(br)
+		if err != nil {
+			return err
+		}
+		buf, err := readAddressBytes(br, extra)
+		if err != nil {
+			return err
+		}
+		addr, err := NewFromBytes(buf)
+		if err != nil {
+			return err
+		}
+		*a = addr
+	} else if err := a.UnmarshalCBOR(br); err != nil {
+		return err
+	}
+
+	if br.Len() != 0 {
+		return fmt.Errorf("unexpected data after address")
+	}
+	return nil
 }
 
 // Marshal marshals the address to cbor.
+//
+// Unlike MarshalCBOR, Marshal encodes the address bytes as a text string, as
+// the refmt atlas entry registered by the cboratlas package does, and
+// encodes Undef as an empty string.
 func (a Address) Marshal() ([]byte, error) {
-	return cbor.DumpObject(a)
+	return append(cbg.CborEncodeMajorType(cbg.MajTextString, uint64(len(a.str))), a.str...), nil
 }
 
 // UnmarshalJSON implements the json unmarshal interface.
@@ -140,6 +151,60 @@ func (a Address) MarshalJSON() ([]byte, error) {
 	return []byte(`"` + a.String() + `"`), nil
 }
 
+// AddressParseError is returned by UnmarshalText when the input is not an
+// address.
+type AddressParseError struct {
+	// Input is the string that was being parsed.
+	Input string
+	// Err is the reason given by the address parser.
+	Err error
+}
+
+func (e *AddressParseError) Error() string {
+	return fmt.Sprintf("invalid address %q: %s", e.Input, e.Err)
+}
+
+func (e *AddressParseError) Unwrap() error {
+	return e.Err
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so addresses can be
+// decoded from configuration formats such as YAML and TOML. It accepts the
+// string form of addresses and the pretty form of hierarchical addresses
+// (<subnet>:<address>, see PrettyPrint). Errors are *AddressParseError.
+func (a *Address) UnmarshalText(text []byte) error {
+	s := string(text)
+	addr, err := parseText(s)
+	if err != nil {
+		return &AddressParseError{Input: s, Err: err}
+	}
+	*a = addr
+	return nil
+}
+
+func parseText(s string) (Address, error) {
+	i := strings.LastIndex(s, HCAddrSeparator)
+	if i < 0 {
+		return decode(s)
+	}
+
+	sn, err := SubnetIDFromString(s[:i])
+	if err != nil {
+		return Undef, xerrors.Errorf("parsing subnet: %w", err)
+	}
+	raw, err := decode(s[i+1:])
+	if err != nil {
+		return Undef, err
+	}
+	return NewHCAddress(sn, raw)
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns the string form
+// of the address.
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
 func (a *Address) Scan(value interface{}) error {
 	switch value := value.(type) {
 	case string:
@@ -179,8 +244,19 @@ func NewBLSAddress(pubkey []byte) (Address, error) {
 	return newAddress(BLS, pubkey)
 }
 
-// NewHAddress returns an address using the Hierarchical protocol.
+// NewHCAddress returns an address using the Hierarchical protocol.
+//
+// The subnet must be in its canonical form (see SubnetID.Validate).
 func NewHCAddress(subnet SubnetID, addr Address) (Address, error) {
+	if err := subnet.Validate(); err != nil {
+		return Undef, err
+	}
+	switch addr.Protocol() {
+	case Unknown:
+		return Undef, ErrUndefRawAddress
+	case Hierarchical:
+		return Undef, ErrNestedHierarchical
+	}
 	// Fix LENGTH container for hierarchical addresses
 	// for RUST compatibility
 	cont := make([]byte, HierarchicalLength)
@@ -191,7 +267,7 @@ func NewHCAddress(subnet SubnetID, addr Address) (Address, error) {
 	if subnet == RootSubnet {
 		snB = []byte(RootStr)
 	} else {
-		snB = []byte(subnet.String())
+		snB = []byte(subnet.CanonicalString())
 	}
 	addrB = addr.Bytes()
 	snSize := varint.ToUvarint(uint64(len(snB)))
@@ -232,56 +308,80 @@ func addressHash(ingest []byte) []byte {
 	return hash(ingest, payloadHashConfig)
 }
 
+func newAddress(protocol Protocol, payload []byte) (Address, error) {
+	payload, err := checkPayload(protocol, payload)
+	if err != nil {
+		return Undef, err
+	}
+
+	explen := 1 + len(payload)
+	buf := make([]byte, explen)
+
+	buf[0] = protocol
+	copy(buf[1:], payload)
+
+	return Address{string(buf)}, nil
+}
+
+// checkPayload validates the payload of an address of the given protocol, and
+// returns it truncated to its significant bytes.
 // FIXME: This needs to be unified with the logic of `decode` (which would
 //  handle the initial verification of the checksum separately), both are doing
 //  the exact same length checks.
-func newAddress(protocol Protocol, payload []byte) (Address, error) {
+func checkPayload(protocol Protocol, payload []byte) ([]byte, error) {
 	switch protocol {
 	case ID:
 		v, n, err := varint.FromUvarint(payload)
 		if err != nil {
-			return Undef, xerrors.Errorf("could not decode: %v: %w", err, ErrInvalidPayload)
+			return nil, xerrors.Errorf("could not decode: %v: %w", err, ErrInvalidPayload)
 		}
 		if n != len(payload) {
-			return Undef, xerrors.Errorf("different varint length (v:%d != p:%d): %w",
+			return nil, xerrors.Errorf("different varint length (v:%d != p:%d): %w",
 				n, len(payload), ErrInvalidLength)
 		}
 		if v > math.MaxInt64 {
-			return Undef, xerrors.Errorf("id addresses must be less than 2^63: %w", ErrInvalidPayload)
+			return nil, xerrors.Errorf("id addresses must be less than 2^63: %w", ErrInvalidPayload)
 		}
 	case SECP256K1, Actor:
 		if len(payload) != PayloadHashLength {
-			return Undef, ErrInvalidLength
+			return nil, ErrInvalidLength
 		}
 	case BLS:
 		if len(payload) != BlsPublicKeyBytes {
-			return Undef, ErrInvalidLength
+			return nil, ErrInvalidLength
 		}
 	case Hierarchical:
 		// 5 bytes for /root + 2 for size + 1 for address
-		if len(payload) < 9 {
-			return Undef, ErrInvalidLength
+		if len(payload) < 9 || len(payload) > HierarchicalLength {
+			return nil, ErrInvalidLength
 		}
 		snSize, _, err := varint.FromUvarint(payload[0:1])
 		if err != nil {
-			return Undef, err
+			return nil, err
 		}
 		addrSize, _, err := varint.FromUvarint(payload[1:2])
 		if err != nil {
-			return Undef, err
+			return nil, err
+		}
+		if snSize+addrSize+2 > uint64(len(payload)) {
+			return nil, ErrInvalidLength
 		}
 		// truncate payload address to the right size
 		payload = payload[:snSize+addrSize+2]
+		raw := payload[snSize+2:]
+		if len(raw) == 0 {
+			return nil, ErrUndefRawAddress
+		}
+		if raw[0] == Hierarchical {
+			return nil, ErrNestedHierarchical
+		}
+		if _, err := checkPayload(raw[0], raw[1:]); err != nil {
+			return nil, xerrors.Errorf("%s: %w", err, ErrInvalidRawAddress)
+		}
 	default:
-		return Undef, ErrUnknownProtocol
+		return nil, ErrUnknownProtocol
 	}
-	explen := 1 + len(payload)
-	buf := make([]byte, explen)
-
-	buf[0] = protocol
-	copy(buf[1:], payload)
-
-	return Address{string(buf)}, nil
+	return payload, nil
 }
 
 func encode(network Network, addr Address) (string, error) {
@@ -390,6 +490,12 @@ func decode(a string) (Address, error) {
 		}
 	}
 
+	if protocol == Hierarchical {
+		if len(payload) > HierarchicalLength {
+			return Undef, ErrInvalidLength
+		}
+	}
+
 	if !ValidateChecksum(append([]byte{protocol}, payload...), cksm) {
 		return Undef, ErrInvalidChecksum
 	}
@@ -424,7 +530,18 @@ func (a *Address) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// MarshalCBOR encodes the address bytes as a CBOR byte string, and a nil
+// address as null. Undef cannot be encoded.
 func (a *Address) MarshalCBOR(w io.Writer) error {
+	scratch := scratchPool.Get().(*[MaxAddressCBORBytes]byte)
+	defer scratchPool.Put(scratch)
+	return a.MarshalCBORBuf(w, scratch[:])
+}
+
+// MarshalCBORBuf is MarshalCBOR using the scratch space of the caller, which
+// must hold at least MaxAddressCBORBytes bytes. It does not allocate, so the
+// same scratch space can be reused to encode many addresses.
+func (a *Address) MarshalCBORBuf(w io.Writer, scratch []byte) error {
 	if a == nil {
 		_, err := w.Write(cbg.CborNull)
 		return err
@@ -434,49 +551,50 @@ func (a *Address) MarshalCBOR(w io.Writer) error {
 		return fmt.Errorf("cannot marshal undefined address")
 	}
 
-	if err := cbg.WriteMajorTypeHeader(w, cbg.MajByteString, uint64(len(a.str))); err != nil {
+	if len(scratch) < MaxAddressCBORBytes {
+		return fmt.Errorf("scratch space too small to marshal an address")
+	}
+
+	if err := cbg.WriteMajorTypeHeaderBuf(scratch, w, cbg.MajByteString, uint64(len(a.str))); err != nil {
 		return err
 	}
 
-	if _, err := io.WriteString(w, a.str); err != nil {
+	// Copying the string avoids a conversion for writers that do not
+	// implement io.StringWriter.
+	n := copy(scratch, a.str)
+	if _, err := w.Write(scratch[:n]); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// UnmarshalCBOR decodes an address encoded by MarshalCBOR. Empty byte strings
+// and null, the encoding of a nil address, are rejected. DecodeOptions can
+// accept them as Undef or restrict the addresses decoded.
 func (a *Address) UnmarshalCBOR(r io.Reader) error {
-	br := cbg.GetPeeker(r)
-
-	maj, extra, err := cbg.CborReadHeader(br)
-	if err != nil {
-		return err
-	}
-
-	if maj != cbg.MajByteString {
-		return fmt.Errorf("cbor type for address unmarshal was not byte string")
-	}
+	return DecodeOptions{}.UnmarshalCBOR(r, a)
+}
 
-	if extra > 128 {
-		return fmt.Errorf("too many bytes to unmarshal for an address")
-	}
+// UnmarshalCBORBuf is UnmarshalCBOR using the scratch space of the caller,
+// which must hold at least MaxAddressCBORBytes bytes. It only allocates the
+// string of the address, so the same scratch space can be reused to decode
+// many addresses.
+func (a *Address) UnmarshalCBORBuf(r io.Reader, scratch []byte) error {
+	return DecodeOptions{}.UnmarshalCBORBuf(r, a, scratch)
+}
 
-	buf := make([]byte, int(extra))
-	if _, err := io.ReadFull(br, buf); err != nil {
-		return err
+// readAddressBytes reads the n bytes of an address following a CBOR header.
+func readAddressBytes(r io.Reader, n uint64) ([]byte, error) {
+	if n > MaxAddressBytes {
+		return nil, fmt.Errorf("too many bytes to unmarshal for an address")
 	}
 
-	addr, err := NewFromBytes(buf)
-	if err != nil {
-		return err
+	buf := make([]byte, int(n))
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
 	}
-	if addr == Undef {
-		return fmt.Errorf("cbor input should not contain empty addresses")
-	}
-
-	*a = addr
-
-	return nil
+	return buf, nil
 }
 
 func IDFromAddress(addr Address) (uint64, error) {
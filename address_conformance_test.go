@@ -0,0 +1,249 @@
+package address_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// conformanceAddresses returns an address of every protocol, with the edge
+// cases of each encoding.
+func conformanceAddresses(t *testing.T) map[string]address.Address {
+	must := func(a address.Address, err error) address.Address {
+		require.NoError(t, err)
+		return a
+	}
+
+	id0 := must(address.NewIDAddress(0))
+	id1000 := must(address.NewIDAddress(1000))
+	deep := address.RootSubnet
+	for i := uint64(100); i < 110; i++ {
+		deep = address.NewSubnetID(deep, must(address.NewIDAddress(i)))
+	}
+	long := address.RootSubnet
+	for i := 0; i < 8; i++ {
+		long = address.NewSubnetID(long, must(address.NewIDAddress(1000000)))
+	}
+	bls := must(address.NewBLSAddress(bytes.Repeat([]byte{0xa0}, address.BlsPublicKeyBytes)))
+
+	return map[string]address.Address{
+		"id 0":      id0,
+		"id 1000":   id1000,
+		"id max":    must(address.NewIDAddress(math.MaxInt64)),
+		"secp256k1": must(address.NewSecp256k1Address(bytes.Repeat([]byte{4}, 65))),
+		"actor":     must(address.NewActorAddress([]byte("actor"))),
+		"bls":       bls,
+		"hc root":   must(address.NewHCAddress(address.RootSubnet, id1000)),
+		"hc deep":   must(address.NewHCAddress(deep, id0)),
+		"hc long":   must(address.NewHCAddress(long, bls)),
+	}
+}
+
+func TestConformance(t *testing.T) {
+	for name, a := range conformanceAddresses(t) {
+		t.Run(name, func(t *testing.T) {
+			bin, err := a.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, a.Bytes(), bin)
+
+			var buf bytes.Buffer
+			require.NoError(t, a.MarshalCBOR(&buf))
+			cb := buf.Bytes()
+
+			m, err := a.Marshal()
+			require.NoError(t, err)
+
+			// All encodings hold the same bytes, under a byte string header
+			// for MarshalCBOR and a text string header for Marshal.
+			header := cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(bin)))
+			require.Equal(t, append(header, bin...), cb)
+			header = cbg.CborEncodeMajorType(cbg.MajTextString, uint64(len(bin)))
+			require.Equal(t, append(header, bin...), m)
+
+			// Every decoder reads its own encoding, and overwrites the
+			// receiver.
+			x := address.TestAddress
+			require.NoError(t, x.UnmarshalBinary(bin))
+			require.Equal(t, a, x)
+
+			x = address.TestAddress
+			require.NoError(t, x.UnmarshalCBOR(bytes.NewReader(cb)))
+			require.Equal(t, a, x)
+
+			x = address.TestAddress
+			require.NoError(t, x.Unmarshal(m))
+			require.Equal(t, a, x)
+
+			// Unmarshal also reads the encoding of MarshalCBOR.
+			x = address.TestAddress
+			require.NoError(t, x.Unmarshal(cb))
+			require.Equal(t, a, x)
+
+			require.Error(t, x.Unmarshal(append(m, 0)))
+
+			// The variants using scratch space of the caller agree.
+			scratch := make([]byte, address.MaxAddressCBORBytes)
+			buf.Reset()
+			require.NoError(t, a.MarshalCBORBuf(&buf, scratch))
+			require.Equal(t, cb, buf.Bytes())
+
+			x = address.TestAddress
+			require.NoError(t, x.UnmarshalCBORBuf(bytes.NewReader(cb), scratch))
+			require.Equal(t, a, x)
+		})
+	}
+}
+
+func TestConformanceUndef(t *testing.T) {
+	bin, err := address.Undef.MarshalBinary()
+	require.NoError(t, err)
+	require.Empty(t, bin)
+
+	x := address.TestAddress
+	require.NoError(t, x.UnmarshalBinary(bin))
+	require.Equal(t, address.Undef, x)
+
+	var buf bytes.Buffer
+	require.Error(t, address.Undef.MarshalCBOR(&buf))
+
+	m, err := address.Undef.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x60}, m)
+
+	x = address.TestAddress
+	require.NoError(t, x.Unmarshal(m))
+	require.Equal(t, address.Undef, x)
+
+	// A nil address is encoded as null, which is rejected as an address
+	// unless Undef is allowed.
+	var nilAddr *address.Address
+	buf.Reset()
+	require.NoError(t, nilAddr.MarshalCBOR(&buf))
+	require.Equal(t, cbg.CborNull, buf.Bytes())
+
+	x = address.TestAddress
+	require.Error(t, x.UnmarshalCBOR(bytes.NewReader(buf.Bytes())))
+	require.Error(t, x.UnmarshalCBORBuf(bytes.NewReader(buf.Bytes()), make([]byte, address.MaxAddressCBORBytes)))
+	require.Error(t, x.Unmarshal(buf.Bytes()))
+	require.Equal(t, address.TestAddress, x)
+
+	require.NoError(t, address.DecodeOptions{AllowUndef: true}.UnmarshalCBOR(bytes.NewReader(buf.Bytes()), &x))
+	require.Equal(t, address.Undef, x)
+
+	// Empty byte strings are rejected, unlike empty text strings.
+	require.Error(t, x.UnmarshalCBOR(bytes.NewReader([]byte{0x40})))
+	require.Error(t, x.Unmarshal([]byte{0x40}))
+}
+
+// Hierarchical addresses can be longer than the 128 bytes UnmarshalCBOR used
+// to accept, up to MaxAddressBytes. DecodeOptions.MaxLength restores the
+// previous limit.
+func TestConformanceMaxLength(t *testing.T) {
+	long := conformanceAddresses(t)["hc long"]
+	require.Greater(t, len(long.Bytes()), 128)
+	require.LessOrEqual(t, len(long.Bytes()), address.MaxAddressBytes)
+
+	var buf bytes.Buffer
+	require.NoError(t, long.MarshalCBOR(&buf))
+	var x address.Address
+	require.NoError(t, x.UnmarshalCBOR(bytes.NewReader(buf.Bytes())))
+	require.Equal(t, long, x)
+
+	x = address.Undef
+	require.Error(t, address.DecodeOptions{MaxLength: 128}.UnmarshalCBOR(bytes.NewReader(buf.Bytes()), &x))
+	require.Equal(t, address.Undef, x)
+}
+
+func TestConformanceInvalid(t *testing.T) {
+	invalid := map[string][]byte{
+		"unknown protocol": {0x09, 0x01},
+		"short":            {0x01, 0x01},
+		"no payload":       {0x00},
+		"bad id":           {0x00, 0x80},
+		"too long":         bytes.Repeat([]byte{0x04}, address.MaxAddressBytes+1),
+	}
+
+	for name, bin := range invalid {
+		t.Run(name, func(t *testing.T) {
+			var x address.Address
+			require.Error(t, x.UnmarshalBinary(bin))
+
+			cb := append(cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(bin))), bin...)
+			require.Error(t, x.UnmarshalCBOR(bytes.NewReader(cb)))
+			require.Error(t, x.UnmarshalCBORBuf(bytes.NewReader(cb), make([]byte, address.MaxAddressCBORBytes)))
+			require.Error(t, x.Unmarshal(cb))
+
+			m := append(cbg.CborEncodeMajorType(cbg.MajTextString, uint64(len(bin))), bin...)
+			require.Error(t, x.Unmarshal(m))
+
+			require.Equal(t, address.Undef, x)
+		})
+	}
+
+	var x address.Address
+	for _, in := range [][]byte{
+		{},
+		{0x01},
+		{0x42, 0x00},
+		{0x58, 0x02, 0x00, 0x01},
+		{0x5f, 0x42, 0x00, 0x01, 0xff},
+	} {
+		require.Error(t, x.UnmarshalCBOR(bytes.NewReader(in)), in)
+		require.Error(t, x.UnmarshalCBORBuf(bytes.NewReader(in), make([]byte, address.MaxAddressCBORBytes)), in)
+		require.Error(t, x.Unmarshal(in), in)
+	}
+}
+
+func TestCBORBufAllocs(t *testing.T) {
+	scratch := make([]byte, address.MaxAddressCBORBytes)
+	for name, a := range conformanceAddresses(t) {
+		var buf bytes.Buffer
+		buf.Grow(address.MaxAddressCBORBytes)
+		allocs := testing.AllocsPerRun(100, func() {
+			buf.Reset()
+			if err := a.MarshalCBORBuf(&buf, scratch); err != nil {
+				t.Fatal(err)
+			}
+		})
+		require.Zero(t, allocs, name)
+
+		// MarshalCBOR takes its scratch space from a pool.
+		allocs = testing.AllocsPerRun(100, func() {
+			buf.Reset()
+			if err := a.MarshalCBOR(&buf); err != nil {
+				t.Fatal(err)
+			}
+		})
+		require.Zero(t, allocs, name)
+
+		// Only the string of the address is allocated.
+		var x address.Address
+		r := bytes.NewReader(buf.Bytes())
+		allocs = testing.AllocsPerRun(100, func() {
+			r.Reset(buf.Bytes())
+			if err := x.UnmarshalCBORBuf(r, scratch); err != nil {
+				t.Fatal(err)
+			}
+		})
+		require.Equal(t, float64(1), allocs, name)
+
+		// So does UnmarshalCBOR, which also takes its scratch space from a
+		// pool. The string is the result, and cannot be avoided.
+		allocs = testing.AllocsPerRun(100, func() {
+			r.Reset(buf.Bytes())
+			if err := x.UnmarshalCBOR(r); err != nil {
+				t.Fatal(err)
+			}
+		})
+		require.Equal(t, float64(1), allocs, name)
+	}
+
+	var x address.Address
+	require.Error(t, x.UnmarshalCBORBuf(bytes.NewReader([]byte{0x40}), make([]byte, 8)))
+	require.Error(t, address.TestAddress.MarshalCBORBuf(new(bytes.Buffer), make([]byte, 8)))
+}
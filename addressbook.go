@@ -0,0 +1,315 @@
+package address
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+)
+
+// AddressBookEntry maps a raw key address to its ID address in a subnet.
+type AddressBookEntry struct {
+	Subnet SubnetID
+	Key    Address
+	ID     Address
+}
+
+// AddressBookStore is the backing store of an AddressBook.
+//
+// Implementations are expected to keep a single entry per (subnet, key) and
+// per (subnet, ID) pair, replacing any previous entry sharing either of them
+// on Put.
+type AddressBookStore interface {
+	// Put stores an entry.
+	Put(e AddressBookEntry) error
+	// Get returns the entry for a key address in a subnet.
+	Get(subnet SubnetID, key Address) (AddressBookEntry, bool, error)
+	// GetByID returns the entry for an ID address in a subnet.
+	GetByID(subnet SubnetID, id Address) (AddressBookEntry, bool, error)
+	// ForEach iterates over the entries of a subnet.
+	ForEach(subnet SubnetID, cb func(AddressBookEntry) error) error
+	// Subnets returns the subnets with at least one entry.
+	Subnets() ([]SubnetID, error)
+}
+
+// AddressBook resolves raw key addresses to their ID address in each subnet.
+type AddressBook struct {
+	store AddressBookStore
+}
+
+// NewAddressBook returns an address book kept in memory.
+func NewAddressBook() *AddressBook {
+	return NewAddressBookWithStore(NewMemAddressBookStore())
+}
+
+// NewAddressBookWithStore returns an address book backed by the given store.
+func NewAddressBookWithStore(store AddressBookStore) *AddressBook {
+	return &AddressBook{store: store}
+}
+
+// Put records that key resolves to id in the subnet.
+func (ab *AddressBook) Put(subnet SubnetID, key, id Address) error {
+	e := AddressBookEntry{Subnet: subnet, Key: key, ID: id}
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	return ab.store.Put(e)
+}
+
+// Lookup returns the ID address of a key address in the subnet.
+func (ab *AddressBook) Lookup(subnet SubnetID, key Address) (Address, error) {
+	e, ok, err := ab.store.Get(subnet, key)
+	if err != nil {
+		return Undef, err
+	}
+	if !ok {
+		return Undef, xerrors.Errorf("key %s in subnet %s: %w", key, subnet, ErrAddressNotFound)
+	}
+	return e.ID, nil
+}
+
+// LookupKey returns the key address an ID address resolves to in the subnet.
+func (ab *AddressBook) LookupKey(subnet SubnetID, id Address) (Address, error) {
+	e, ok, err := ab.store.GetByID(subnet, id)
+	if err != nil {
+		return Undef, err
+	}
+	if !ok {
+		return Undef, xerrors.Errorf("id %s in subnet %s: %w", id, subnet, ErrAddressNotFound)
+	}
+	return e.Key, nil
+}
+
+// Resolve returns the ID address for the raw address of a hierarchical
+// address in its subnet.
+func (ab *AddressBook) Resolve(a Address) (Address, error) {
+	sn, err := a.Subnet()
+	if err != nil {
+		return Undef, err
+	}
+	raw, err := a.RawAddr()
+	if err != nil {
+		return Undef, err
+	}
+	if raw.Protocol() == ID {
+		return raw, nil
+	}
+	return ab.Lookup(sn, raw)
+}
+
+// ForEach iterates over the entries of the subnet.
+func (ab *AddressBook) ForEach(subnet SubnetID, cb func(key, id Address) error) error {
+	return ab.store.ForEach(subnet, func(e AddressBookEntry) error {
+		return cb(e.Key, e.ID)
+	})
+}
+
+// Subnets returns the subnets with at least one entry in the address book.
+func (ab *AddressBook) Subnets() ([]SubnetID, error) {
+	return ab.store.Subnets()
+}
+
+// Import reads entries written by Export and adds them to the address book.
+// Entries are all decoded and validated before any of them is added, so the
+// address book is left unchanged if the input is invalid.
+func (ab *AddressBook) Import(r io.Reader) error {
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return xerrors.Errorf("cbor input should be of type array")
+	}
+
+	var entries []AddressBookEntry
+	for i := uint64(0); i < extra; i++ {
+		var e AddressBookEntry
+		if err := e.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling entry %d: %w", i, err)
+		}
+		if err := e.Validate(); err != nil {
+			return xerrors.Errorf("importing entry %d: %w", i, err)
+		}
+		entries = append(entries, e)
+	}
+
+	for _, e := range entries {
+		if err := ab.store.Put(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Export writes every entry of the address book as a CBOR array of
+// AddressBookEntry, sorted by subnet key then by the bytes of the key
+// address, so that the same address book always has the same export.
+func (ab *AddressBook) Export(w io.Writer) error {
+	subnets, err := ab.store.Subnets()
+	if err != nil {
+		return err
+	}
+	var entries []AddressBookEntry
+	for _, sn := range subnets {
+		if err := ab.store.ForEach(sn, func(e AddressBookEntry) error {
+			entries = append(entries, e)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		ki, kj := entries[i].Subnet.Key(), entries[j].Subnet.Key()
+		if ki != kj {
+			return ki < kj
+		}
+		return bytes.Compare(entries[i].Key.Bytes(), entries[j].Key.Bytes()) < 0
+	})
+
+	if err := cbg.WriteMajorTypeHeader(w, cbg.MajArray, uint64(len(entries))); err != nil {
+		return err
+	}
+	for i := range entries {
+		if err := entries[i].MarshalCBOR(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that the entry maps a raw key address to an ID address in
+// a valid subnet.
+func (e AddressBookEntry) Validate() error {
+	if err := e.Subnet.Validate(); err != nil {
+		return err
+	}
+	switch e.Key.Protocol() {
+	case SECP256K1, Actor, BLS:
+	default:
+		return xerrors.Errorf("key %s is not a raw key address: %w", e.Key, ErrInvalidAddressBookEntry)
+	}
+	if e.ID.Protocol() != ID {
+		return xerrors.Errorf("id %s is not an ID address: %w", e.ID, ErrInvalidAddressBookEntry)
+	}
+	return nil
+}
+
+type memSubnetEntries struct {
+	subnet SubnetID
+	byKey  map[Address]Address
+	byID   map[Address]Address
+}
+
+// MemAddressBookStore is an in-memory AddressBookStore. It is safe for
+// concurrent use.
+type MemAddressBookStore struct {
+	lk      sync.RWMutex
+	subnets map[string]*memSubnetEntries
+	order   []string
+}
+
+var _ AddressBookStore = (*MemAddressBookStore)(nil)
+
+// NewMemAddressBookStore returns an empty in-memory store.
+func NewMemAddressBookStore() *MemAddressBookStore {
+	return &MemAddressBookStore{subnets: make(map[string]*memSubnetEntries)}
+}
+
+func (s *MemAddressBookStore) Put(e AddressBookEntry) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	k := e.Subnet.Key()
+	sn, ok := s.subnets[k]
+	if !ok {
+		sn = &memSubnetEntries{
+			subnet: e.Subnet,
+			byKey:  make(map[Address]Address),
+			byID:   make(map[Address]Address),
+		}
+		s.subnets[k] = sn
+		s.order = append(s.order, k)
+	}
+	// drop any mapping that shares the key or the ID with the new entry.
+	if old, ok := sn.byKey[e.Key]; ok {
+		delete(sn.byID, old)
+	}
+	if old, ok := sn.byID[e.ID]; ok {
+		delete(sn.byKey, old)
+	}
+	sn.byKey[e.Key] = e.ID
+	sn.byID[e.ID] = e.Key
+	return nil
+}
+
+func (s *MemAddressBookStore) Get(subnet SubnetID, key Address) (AddressBookEntry, bool, error) {
+	s.lk.RLock()
+	defer s.lk.RUnlock()
+
+	sn, ok := s.subnets[subnet.Key()]
+	if !ok {
+		return AddressBookEntry{}, false, nil
+	}
+	id, ok := sn.byKey[key]
+	if !ok {
+		return AddressBookEntry{}, false, nil
+	}
+	return AddressBookEntry{Subnet: sn.subnet, Key: key, ID: id}, true, nil
+}
+
+func (s *MemAddressBookStore) GetByID(subnet SubnetID, id Address) (AddressBookEntry, bool, error) {
+	s.lk.RLock()
+	defer s.lk.RUnlock()
+
+	sn, ok := s.subnets[subnet.Key()]
+	if !ok {
+		return AddressBookEntry{}, false, nil
+	}
+	key, ok := sn.byID[id]
+	if !ok {
+		return AddressBookEntry{}, false, nil
+	}
+	return AddressBookEntry{Subnet: sn.subnet, Key: key, ID: id}, true, nil
+}
+
+// ForEach iterates over the entries of the subnet in the order of the bytes
+// of their key address. The callback runs without holding the lock of the
+// store, so it may modify it.
+func (s *MemAddressBookStore) ForEach(subnet SubnetID, cb func(AddressBookEntry) error) error {
+	s.lk.RLock()
+	var entries []AddressBookEntry
+	if sn, ok := s.subnets[subnet.Key()]; ok {
+		entries = make([]AddressBookEntry, 0, len(sn.byKey))
+		for key, id := range sn.byKey {
+			entries = append(entries, AddressBookEntry{Subnet: sn.subnet, Key: key, ID: id})
+		}
+	}
+	s.lk.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].Key.Bytes(), entries[j].Key.Bytes()) < 0
+	})
+	for _, e := range entries {
+		if err := cb(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemAddressBookStore) Subnets() ([]SubnetID, error) {
+	s.lk.RLock()
+	defer s.lk.RUnlock()
+
+	out := make([]SubnetID, 0, len(s.order))
+	for _, k := range s.order {
+		out = append(out, s.subnets[k].subnet)
+	}
+	return out, nil
+}
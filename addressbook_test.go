@@ -0,0 +1,166 @@
+package address_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func TestAddressBook(t *testing.T) {
+	id1, _ := address.NewIDAddress(1)
+	id100, _ := address.NewIDAddress(100)
+	id101, _ := address.NewIDAddress(101)
+	key1, err := address.NewActorAddress([]byte("key1"))
+	require.NoError(t, err)
+	key2, err := address.NewActorAddress([]byte("key2"))
+	require.NoError(t, err)
+	net1 := address.NewSubnetID(address.RootSubnet, id1)
+
+	ab := address.NewAddressBook()
+	require.NoError(t, ab.Put(address.RootSubnet, key1, id100))
+	require.NoError(t, ab.Put(net1, key1, id101))
+	require.NoError(t, ab.Put(net1, key2, id100))
+
+	t.Log("Test lookups per subnet")
+	id, err := ab.Lookup(address.RootSubnet, key1)
+	require.NoError(t, err)
+	require.Equal(t, id100, id)
+	id, err = ab.Lookup(net1, key1)
+	require.NoError(t, err)
+	require.Equal(t, id101, id)
+	_, err = ab.Lookup(address.RootSubnet, key2)
+	require.ErrorIs(t, err, address.ErrAddressNotFound)
+
+	t.Log("Test reverse lookups")
+	key, err := ab.LookupKey(net1, id100)
+	require.NoError(t, err)
+	require.Equal(t, key2, key)
+	_, err = ab.LookupKey(address.RootSubnet, id101)
+	require.ErrorIs(t, err, address.ErrAddressNotFound)
+
+	t.Log("Test hierarchical resolution")
+	ha, err := address.NewHCAddress(net1, key1)
+	require.NoError(t, err)
+	id, err = ab.Resolve(ha)
+	require.NoError(t, err)
+	require.Equal(t, id101, id)
+	_, err = ab.Resolve(key1)
+	require.ErrorIs(t, err, address.ErrNotHierarchical)
+
+	t.Log("Test remapping replaces previous entries")
+	require.NoError(t, ab.Put(net1, key1, id100))
+	_, err = ab.LookupKey(net1, id101)
+	require.ErrorIs(t, err, address.ErrAddressNotFound)
+	_, err = ab.Lookup(net1, key2)
+	require.ErrorIs(t, err, address.ErrAddressNotFound)
+
+	t.Log("Test invalid entries")
+	require.ErrorIs(t, ab.Put(net1, id1, id100), address.ErrInvalidAddressBookEntry)
+	require.ErrorIs(t, ab.Put(net1, ha, id100), address.ErrInvalidAddressBookEntry)
+	require.ErrorIs(t, ab.Put(net1, key1, key2), address.ErrInvalidAddressBookEntry)
+	require.Error(t, ab.Put(address.UndefSubnetID, key1, id100))
+
+	t.Log("Test iteration by subnet")
+	subnets, err := ab.Subnets()
+	require.NoError(t, err)
+	require.Equal(t, []address.SubnetID{address.RootSubnet, net1}, subnets)
+	entries := map[address.Address]address.Address{}
+	require.NoError(t, ab.ForEach(net1, func(key, id address.Address) error {
+		entries[key] = id
+		return nil
+	}))
+	require.Equal(t, map[address.Address]address.Address{key1: id100}, entries)
+}
+
+func TestAddressBookExportImport(t *testing.T) {
+	id1, _ := address.NewIDAddress(1)
+	net1 := address.NewSubnetID(address.RootSubnet, id1)
+	getter := address.NewForTestGetter()
+
+	ab := address.NewAddressBook()
+	for i := uint64(0); i < 10; i++ {
+		id, _ := address.NewIDAddress(100 + i)
+		require.NoError(t, ab.Put(address.RootSubnet, getter(), id))
+		require.NoError(t, ab.Put(net1, getter(), id))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, ab.Export(&buf))
+
+	imported := address.NewAddressBook()
+	require.NoError(t, imported.Import(bytes.NewReader(buf.Bytes())))
+
+	var again bytes.Buffer
+	require.NoError(t, imported.Export(&again))
+
+	for _, sn := range []address.SubnetID{address.RootSubnet, net1} {
+		require.NoError(t, ab.ForEach(sn, func(key, id address.Address) error {
+			got, err := imported.Lookup(sn, key)
+			require.NoError(t, err)
+			require.Equal(t, id, got)
+			return nil
+		}))
+	}
+	// Exports are deterministic.
+	require.Equal(t, buf.Bytes(), again.Bytes())
+	for i := 0; i < 10; i++ {
+		var b bytes.Buffer
+		require.NoError(t, ab.Export(&b))
+		require.Equal(t, buf.Bytes(), b.Bytes())
+	}
+
+	// Invalid entries are rejected on import, without importing the valid
+	// entries before them.
+	id100, _ := address.NewIDAddress(100)
+	key := getter()
+	var bad bytes.Buffer
+	bad.WriteByte(0x82) // array of two entries
+	e := address.AddressBookEntry{Subnet: net1, Key: key, ID: id100}
+	require.NoError(t, e.MarshalCBOR(&bad))
+	e = address.AddressBookEntry{Subnet: net1, Key: id1, ID: id1}
+	require.NoError(t, e.MarshalCBOR(&bad))
+	ab = address.NewAddressBook()
+	require.ErrorIs(t, ab.Import(&bad), address.ErrInvalidAddressBookEntry)
+	_, err := ab.Lookup(net1, key)
+	require.ErrorIs(t, err, address.ErrAddressNotFound)
+	subnets, err := ab.Subnets()
+	require.NoError(t, err)
+	require.Empty(t, subnets)
+}
+
+func TestAddressBookConcurrent(t *testing.T) {
+	ab := address.NewAddressBook()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				n := uint64(i*100 + j)
+				key, err := address.NewActorAddress([]byte(fmt.Sprint(n)))
+				require.NoError(t, err)
+				id, _ := address.NewIDAddress(n)
+				require.NoError(t, ab.Put(address.RootSubnet, key, id))
+				got, err := ab.Lookup(address.RootSubnet, key)
+				require.NoError(t, err)
+				require.Equal(t, id, got)
+				require.NoError(t, ab.Export(io.Discard))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	n := 0
+	require.NoError(t, ab.ForEach(address.RootSubnet, func(key, id address.Address) error {
+		n++
+		return nil
+	}))
+	require.Equal(t, 800, n)
+}
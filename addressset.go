@@ -0,0 +1,466 @@
+package address
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/multiformats/go-varint"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+)
+
+// MaxAddressSetBytes is the max length of an encoded address set read from
+// CBOR.
+const MaxAddressSetBytes = 32 << 20
+
+// addressSetVersion is the first byte of encoded address sets.
+const addressSetVersion = 1
+
+// addressListIndexInterval is the number of entries between two checkpoints
+// of the index of variable length groups, bounding the entries decoded by
+// AddressList.At.
+const addressListIndexInterval = 64
+
+// AddressSet is a set of addresses with a compact binary encoding, for large
+// sets such as allow-lists.
+//
+// Encoded sets hold a version byte and the number of protocol groups,
+// followed by the groups in protocol order. Each group holds its protocol
+// byte, the number of addresses and the length of its payload (as uvarints),
+// then the payloads of its addresses in set order:
+//
+//   - ID payloads as uvarints, the first one holding the ID and the next ones
+//     the difference with the previous ID,
+//   - SECP256K1, Actor and BLS payloads without length prefixes, as they have
+//     a fixed size,
+//   - Hierarchical payloads prefixed by their length as a uvarint.
+//
+// Sets are kept in the order of Compare, so every set has a single encoding.
+// MarshalCBOR wraps the encoding in a CBOR byte string.
+type AddressSet struct {
+	// addrs holds the addresses in set order, without duplicates.
+	addrs []Address
+}
+
+// NewAddressSet returns a set holding the given addresses.
+func NewAddressSet(addrs ...Address) (*AddressSet, error) {
+	s := &AddressSet{addrs: make([]Address, 0, len(addrs))}
+	for _, a := range addrs {
+		if a == Undef {
+			return nil, xerrors.Errorf("cannot add undefined address to a set: %w", ErrInvalidAddressSet)
+		}
+		s.addrs = append(s.addrs, a)
+	}
+
+	SortAddresses(s.addrs)
+	uniq := s.addrs[:0]
+	for i, a := range s.addrs {
+		if i == 0 || a != s.addrs[i-1] {
+			uniq = append(uniq, a)
+		}
+	}
+	s.addrs = uniq
+	return s, nil
+}
+
+// Add adds an address to the set.
+func (s *AddressSet) Add(a Address) error {
+	if a == Undef {
+		return xerrors.Errorf("cannot add undefined address to a set: %w", ErrInvalidAddressSet)
+	}
+	i := SearchAddresses(s.addrs, a)
+	if i < len(s.addrs) && s.addrs[i] == a {
+		return nil
+	}
+	s.addrs = append(s.addrs, Undef)
+	copy(s.addrs[i+1:], s.addrs[i:])
+	s.addrs[i] = a
+	return nil
+}
+
+// Has returns true if the set holds a.
+func (s *AddressSet) Has(a Address) bool {
+	return ContainsAddress(s.addrs, a)
+}
+
+// Len returns the number of addresses in the set.
+func (s *AddressSet) Len() int {
+	return len(s.addrs)
+}
+
+// Addresses returns the addresses of the set in set order.
+func (s *AddressSet) Addresses() []Address {
+	return append([]Address(nil), s.addrs...)
+}
+
+// MarshalBinary returns the compact encoding of the set.
+func (s *AddressSet) MarshalBinary() ([]byte, error) {
+	var groups [][]Address
+	for i, a := range s.addrs {
+		if i == 0 || a.Protocol() != s.addrs[i-1].Protocol() {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], a)
+	}
+
+	buf := []byte{addressSetVersion}
+	buf = append(buf, varint.ToUvarint(uint64(len(groups)))...)
+	for _, g := range groups {
+		var payload []byte
+		switch p := g[0].Protocol(); p {
+		case ID:
+			var prev uint64
+			for _, a := range g {
+				id, err := IDFromAddress(a)
+				if err != nil {
+					return nil, err
+				}
+				payload = append(payload, varint.ToUvarint(id-prev)...)
+				prev = id
+			}
+		case SECP256K1, Actor, BLS:
+			for _, a := range g {
+				payload = append(payload, a.str[1:]...)
+			}
+		default:
+			for _, a := range g {
+				payload = append(payload, varint.ToUvarint(uint64(len(a.str)-1))...)
+				payload = append(payload, a.str[1:]...)
+			}
+		}
+
+		buf = append(buf, g[0].Protocol())
+		buf = append(buf, varint.ToUvarint(uint64(len(g)))...)
+		buf = append(buf, varint.ToUvarint(uint64(len(payload)))...)
+		buf = append(buf, payload...)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a set encoded by MarshalBinary.
+func (s *AddressSet) UnmarshalBinary(b []byte) error {
+	var l AddressList
+	if err := l.UnmarshalBinary(b); err != nil {
+		return err
+	}
+	s.addrs = l.Addresses()
+	return nil
+}
+
+// MarshalCBOR encodes the set as a CBOR byte string holding its compact
+// encoding.
+func (s *AddressSet) MarshalCBOR(w io.Writer) error {
+	b, err := s.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return writeAddressSetCBOR(w, b)
+}
+
+// UnmarshalCBOR decodes a set encoded by MarshalCBOR.
+func (s *AddressSet) UnmarshalCBOR(r io.Reader) error {
+	var l AddressList
+	if err := l.UnmarshalCBOR(r); err != nil {
+		return err
+	}
+	s.addrs = l.Addresses()
+	return nil
+}
+
+func writeAddressSetCBOR(w io.Writer, b []byte) error {
+	if err := cbg.WriteMajorTypeHeader(w, cbg.MajByteString, uint64(len(b))); err != nil {
+		return err
+	}
+	_, err := w.Write(b)
+	return err
+}
+
+// AddressList gives random access to the addresses of an encoded
+// AddressSet, without decoding them all.
+//
+// Decoding validates the whole encoding and indexes it, so that At decodes
+// at most addressListIndexInterval entries.
+type AddressList struct {
+	data   []byte
+	groups []addressListGroup
+	n      int
+}
+
+// addressListGroup locates the addresses of a protocol in an encoded set.
+type addressListGroup struct {
+	protocol Protocol
+	// start is the position of the first address of the group in the set.
+	start int
+	count int
+	// payload holds the encoded payloads of the group.
+	payload []byte
+	// index holds a checkpoint every addressListIndexInterval entries of
+	// variable length groups.
+	index []addressListCheckpoint
+}
+
+// addressListCheckpoint is the offset of an entry in the payload of its
+// group, and for ID groups the ID of the entry before it.
+type addressListCheckpoint struct {
+	offset int
+	prev   uint64
+}
+
+// fixedPayloadLength returns the payload length of protocols whose payloads
+// have a fixed size, or 0.
+func fixedPayloadLength(p Protocol) int {
+	switch p {
+	case SECP256K1, Actor:
+		return PayloadHashLength
+	case BLS:
+		return BlsPublicKeyBytes
+	}
+	return 0
+}
+
+// UnmarshalBinary decodes and indexes a set encoded by
+// AddressSet.MarshalBinary. The list keeps a reference to b.
+func (l *AddressList) UnmarshalBinary(b []byte) error {
+	if len(b) == 0 || b[0] != addressSetVersion {
+		return xerrors.Errorf("unknown version: %w", ErrInvalidAddressSet)
+	}
+	r := bytes.NewReader(b[1:])
+
+	ngroups, err := varint.ReadUvarint(r)
+	if err != nil {
+		return xerrors.Errorf("reading group count: %v: %w", err, ErrInvalidAddressSet)
+	}
+	if ngroups > uint64(r.Len()) {
+		return xerrors.Errorf("%d groups: %w", ngroups, ErrInvalidAddressSet)
+	}
+
+	groups := make([]addressListGroup, 0, ngroups)
+	n := 0
+	for i := uint64(0); i < ngroups; i++ {
+		p, err := r.ReadByte()
+		if err != nil {
+			return xerrors.Errorf("reading protocol: %v: %w", err, ErrInvalidAddressSet)
+		}
+		if len(groups) > 0 && p <= groups[len(groups)-1].protocol {
+			return xerrors.Errorf("protocol %d out of order: %w", p, ErrInvalidAddressSet)
+		}
+		count, err := varint.ReadUvarint(r)
+		if err != nil {
+			return xerrors.Errorf("reading address count: %v: %w", err, ErrInvalidAddressSet)
+		}
+		size, err := varint.ReadUvarint(r)
+		if err != nil {
+			return xerrors.Errorf("reading payload length: %v: %w", err, ErrInvalidAddressSet)
+		}
+		if count == 0 || count > size || size > uint64(r.Len()) {
+			return xerrors.Errorf("%d addresses in %d bytes: %w", count, size, ErrInvalidAddressSet)
+		}
+
+		off := len(b) - r.Len()
+		g := addressListGroup{
+			protocol: p,
+			start:    n,
+			count:    int(count),
+			payload:  b[off : off+int(size)],
+		}
+		if err := g.validate(); err != nil {
+			return xerrors.Errorf("protocol %d: %w", p, err)
+		}
+		groups = append(groups, g)
+		n += g.count
+		if _, err := r.Seek(int64(size), io.SeekCurrent); err != nil {
+			return err
+		}
+	}
+	if r.Len() != 0 {
+		return xerrors.Errorf("unexpected data after address set: %w", ErrInvalidAddressSet)
+	}
+
+	*l = AddressList{data: b, groups: groups, n: n}
+	return nil
+}
+
+// validate checks that the payload of the group holds count valid addresses
+// in set order, and builds the index of variable length groups.
+func (g *addressListGroup) validate() error {
+	if size := fixedPayloadLength(g.protocol); size > 0 {
+		if len(g.payload) != g.count*size {
+			return xerrors.Errorf("%d bytes for %d addresses: %w", len(g.payload), g.count, ErrInvalidAddressSet)
+		}
+		for i := 1; i < g.count; i++ {
+			if bytes.Compare(g.payload[(i-1)*size:i*size], g.payload[i*size:(i+1)*size]) >= 0 {
+				return xerrors.Errorf("address %d out of order: %w", i, ErrInvalidAddressSet)
+			}
+		}
+		return nil
+	}
+
+	if g.protocol != ID && g.protocol != Hierarchical {
+		return ErrUnknownProtocol
+	}
+
+	var (
+		off  int
+		id   uint64
+		prev []byte
+	)
+	for i := 0; i < g.count; i++ {
+		if i%addressListIndexInterval == 0 {
+			g.index = append(g.index, addressListCheckpoint{offset: off, prev: id})
+		}
+		v, n, err := varint.FromUvarint(g.payload[off:])
+		if err != nil {
+			return xerrors.Errorf("address %d: %v: %w", i, err, ErrInvalidAddressSet)
+		}
+		off += n
+
+		if g.protocol == ID {
+			if i > 0 && v == 0 {
+				return xerrors.Errorf("address %d out of order: %w", i, ErrInvalidAddressSet)
+			}
+			if v > math.MaxInt64-id {
+				return xerrors.Errorf("address %d: %w", i, ErrInvalidPayload)
+			}
+			id += v
+			continue
+		}
+
+		if v > uint64(len(g.payload)-off) {
+			return xerrors.Errorf("address %d: %w", i, ErrInvalidAddressSet)
+		}
+		payload := g.payload[off : off+int(v)]
+		off += int(v)
+		checked, err := checkPayload(g.protocol, payload)
+		if err != nil {
+			return xerrors.Errorf("address %d: %w", i, err)
+		}
+		if len(checked) != len(payload) {
+			return xerrors.Errorf("address %d: %w", i, ErrInvalidLength)
+		}
+		if prev != nil && bytes.Compare(prev, payload) >= 0 {
+			return xerrors.Errorf("address %d out of order: %w", i, ErrInvalidAddressSet)
+		}
+		prev = payload
+	}
+	if off != len(g.payload) {
+		return xerrors.Errorf("unexpected data after addresses: %w", ErrInvalidAddressSet)
+	}
+	return nil
+}
+
+// MarshalBinary returns the encoding the list was decoded from.
+func (l *AddressList) MarshalBinary() ([]byte, error) {
+	return l.data, nil
+}
+
+// MarshalCBOR encodes the list as AddressSet.MarshalCBOR does.
+func (l *AddressList) MarshalCBOR(w io.Writer) error {
+	return writeAddressSetCBOR(w, l.data)
+}
+
+// UnmarshalCBOR decodes and indexes a set encoded by AddressSet.MarshalCBOR.
+func (l *AddressList) UnmarshalCBOR(r io.Reader) error {
+	b, err := cbg.ReadByteArray(r, MaxAddressSetBytes)
+	if err != nil {
+		return err
+	}
+	return l.UnmarshalBinary(b)
+}
+
+// Len returns the number of addresses in the list.
+func (l *AddressList) Len() int {
+	return l.n
+}
+
+// At returns the i-th address of the list in set order. It panics if i is
+// out of range.
+func (l *AddressList) At(i int) Address {
+	if i < 0 || i >= l.n {
+		panic(fmt.Sprintf("address list index %d out of range [0:%d]", i, l.n))
+	}
+	g := &l.groups[0]
+	for j := range l.groups {
+		if l.groups[j].start > i {
+			break
+		}
+		g = &l.groups[j]
+	}
+	k := i - g.start
+
+	if size := fixedPayloadLength(g.protocol); size > 0 {
+		return g.address(g.payload[k*size : (k+1)*size])
+	}
+
+	cp := g.index[k/addressListIndexInterval]
+	off, id := cp.offset, cp.prev
+	for j := k - k%addressListIndexInterval; ; j++ {
+		v, n, _ := varint.FromUvarint(g.payload[off:])
+		off += n
+		if g.protocol == ID {
+			id += v
+			if j == k {
+				return g.address(varint.ToUvarint(id))
+			}
+			continue
+		}
+		if j == k {
+			return g.address(g.payload[off : off+int(v)])
+		}
+		off += int(v)
+	}
+}
+
+// ForEach calls cb with every address of the list in set order, and its
+// position.
+func (l *AddressList) ForEach(cb func(i int, a Address) error) error {
+	for gi := range l.groups {
+		g := &l.groups[gi]
+		size := fixedPayloadLength(g.protocol)
+		var (
+			off int
+			id  uint64
+		)
+		for k := 0; k < g.count; k++ {
+			var a Address
+			switch {
+			case size > 0:
+				a = g.address(g.payload[k*size : (k+1)*size])
+			case g.protocol == ID:
+				v, n, _ := varint.FromUvarint(g.payload[off:])
+				off += n
+				id += v
+				a = g.address(varint.ToUvarint(id))
+			default:
+				v, n, _ := varint.FromUvarint(g.payload[off:])
+				off += n
+				a = g.address(g.payload[off : off+int(v)])
+				off += int(v)
+			}
+			if err := cb(g.start+k, a); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Addresses returns all the addresses of the list in set order.
+func (l *AddressList) Addresses() []Address {
+	addrs := make([]Address, 0, l.n)
+	_ = l.ForEach(func(_ int, a Address) error {
+		addrs = append(addrs, a)
+		return nil
+	})
+	return addrs
+}
+
+// address returns the address of the group with the given payload, which
+// was validated when decoding the list.
+func (g *addressListGroup) address(payload []byte) Address {
+	buf := make([]byte, 1+len(payload))
+	buf[0] = g.protocol
+	copy(buf[1:], payload)
+	return Address{string(buf)}
+}
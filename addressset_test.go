@@ -0,0 +1,181 @@
+package address_test
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// testSet returns addresses of every protocol, in set order.
+func testSet(t *testing.T, n int) []address.Address {
+	var (
+		ids, secps, actors, blss, hcs []address.Address
+		id                            uint64
+	)
+	r := rand.New(rand.NewSource(int64(n)))
+	for i := 0; i < n; i++ {
+		id += uint64(r.Intn(1000) + 1)
+		a, err := address.NewIDAddress(id)
+		require.NoError(t, err)
+		ids = append(ids, a)
+
+		key := make([]byte, address.BlsPublicKeyBytes)
+		r.Read(key)
+		a, err = address.NewSecp256k1Address(key)
+		require.NoError(t, err)
+		secps = append(secps, a)
+		a, err = address.NewActorAddress(key)
+		require.NoError(t, err)
+		actors = append(actors, a)
+		a, err = address.NewBLSAddress(key)
+		require.NoError(t, err)
+		blss = append(blss, a)
+	}
+	for i := 0; i < n && i < 100; i++ {
+		a, err := address.NewHCAddress(address.NewSubnetID(address.RootSubnet, ids[i]), actors[i])
+		require.NoError(t, err)
+		hcs = append(hcs, a)
+	}
+
+	var addrs []address.Address
+	for _, group := range [][]address.Address{ids, secps, actors, blss, hcs} {
+		set, err := address.NewAddressSet(group...)
+		require.NoError(t, err)
+		addrs = append(addrs, set.Addresses()...)
+	}
+	return addrs
+}
+
+func TestAddressSet(t *testing.T) {
+	addrs := testSet(t, 300)
+
+	// Input order and duplicates do not matter.
+	shuffled := append(append([]address.Address(nil), addrs...), addrs[:10]...)
+	rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	set, err := address.NewAddressSet(shuffled...)
+	require.NoError(t, err)
+	require.Equal(t, addrs, set.Addresses())
+	require.Equal(t, len(addrs), set.Len())
+
+	b, err := set.MarshalBinary()
+	require.NoError(t, err)
+
+	var decoded address.AddressSet
+	require.NoError(t, decoded.UnmarshalBinary(b))
+	require.Equal(t, addrs, decoded.Addresses())
+
+	// The encoding is more compact than an array of byte strings.
+	var arr bytes.Buffer
+	require.NoError(t, cbg.WriteMajorTypeHeader(&arr, cbg.MajArray, uint64(len(addrs))))
+	for _, a := range addrs {
+		require.NoError(t, a.MarshalCBOR(&arr))
+	}
+	require.Less(t, len(b), arr.Len())
+
+	var buf bytes.Buffer
+	require.NoError(t, set.MarshalCBOR(&buf))
+	require.Equal(t, append(cbg.CborEncodeMajorType(cbg.MajByteString, uint64(len(b))), b...), buf.Bytes())
+	decoded = address.AddressSet{}
+	require.NoError(t, decoded.UnmarshalCBOR(bytes.NewReader(buf.Bytes())))
+	require.Equal(t, addrs, decoded.Addresses())
+
+	for _, a := range addrs[:10] {
+		require.True(t, set.Has(a))
+	}
+	id, err := address.NewIDAddress(0)
+	require.NoError(t, err)
+	require.False(t, set.Has(id))
+	require.NoError(t, set.Add(id))
+	require.True(t, set.Has(id))
+	require.Equal(t, id, set.Addresses()[0])
+	require.NoError(t, set.Add(id))
+	require.Equal(t, len(addrs)+1, set.Len())
+
+	require.Error(t, set.Add(address.Undef))
+	_, err = address.NewAddressSet(id, address.Undef)
+	require.ErrorIs(t, err, address.ErrInvalidAddressSet)
+
+	// The empty set.
+	empty, err := address.NewAddressSet()
+	require.NoError(t, err)
+	b, err = empty.MarshalBinary()
+	require.NoError(t, err)
+	require.NoError(t, decoded.UnmarshalBinary(b))
+	require.Zero(t, decoded.Len())
+}
+
+func TestAddressList(t *testing.T) {
+	for _, n := range []int{1, 63, 64, 65, 1000} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			addrs := testSet(t, n)
+			set, err := address.NewAddressSet(addrs...)
+			require.NoError(t, err)
+			b, err := set.MarshalBinary()
+			require.NoError(t, err)
+
+			var l address.AddressList
+			require.NoError(t, l.UnmarshalBinary(b))
+			require.Equal(t, len(addrs), l.Len())
+			for _, i := range rand.Perm(len(addrs)) {
+				require.Equal(t, addrs[i], l.At(i), i)
+			}
+
+			var next int
+			require.NoError(t, l.ForEach(func(i int, a address.Address) error {
+				require.Equal(t, next, i)
+				require.Equal(t, addrs[i], a)
+				next++
+				return nil
+			}))
+			require.Equal(t, len(addrs), next)
+
+			require.Panics(t, func() { l.At(len(addrs)) })
+
+			// The list re-encodes as the set.
+			var buf, lbuf bytes.Buffer
+			require.NoError(t, set.MarshalCBOR(&buf))
+			require.NoError(t, l.MarshalCBOR(&lbuf))
+			require.Equal(t, buf.Bytes(), lbuf.Bytes())
+		})
+	}
+}
+
+func TestAddressListInvalid(t *testing.T) {
+	actor, err := address.NewActorAddress([]byte("actor"))
+	require.NoError(t, err)
+	payload := actor.Payload()
+	maxID := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+
+	for name, b := range map[string][]byte{
+		"empty":            {},
+		"version":          {2, 0},
+		"truncated":        {1, 1, 0, 2},
+		"trailing":         {1, 0, 0},
+		"unknown protocol": {1, 1, 9, 1, 1, 0},
+		"duplicate id":     {1, 1, 0, 2, 2, 5, 0},
+		"id overflow":      append(append([]byte{1, 1, 0, 2, 10}, maxID...), 1),
+		"non-minimal id":   {1, 1, 0, 1, 2, 0x81, 0x00},
+		"fixed size":       append(append([]byte{1, 1, 2, 1, 21}, payload...), 0),
+		"unsorted":         append(append([]byte{1, 1, 2, 2, 40}, bytes.Repeat([]byte{1}, 20)...), bytes.Repeat([]byte{0}, 20)...),
+		"protocol order":   {1, 2, 0, 1, 1, 5, 0, 1, 1, 6},
+		"empty group":      {1, 1, 0, 0, 0},
+		"bad hierarchical": {1, 1, 4, 1, 3, 2, 0, 0},
+	} {
+		var l address.AddressList
+		require.Error(t, l.UnmarshalBinary(b), name)
+	}
+
+	var l address.AddressList
+	require.NoError(t, l.UnmarshalBinary(append([]byte{1, 1, 2, 1, 20}, payload...)))
+	require.Equal(t, actor, l.At(0))
+	require.NoError(t, l.UnmarshalBinary(append([]byte{1, 1, 0, 1, 9}, maxID...)))
+	id, err := address.IDFromAddress(l.At(0))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1<<63-1), id)
+}
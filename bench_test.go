@@ -1,10 +1,14 @@
 package address
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"math/rand"
 
 	"testing"
+
+	cbg "github.com/whyrusleeping/cbor-gen"
 )
 
 func blsaddr(n int64) Address {
@@ -92,3 +96,110 @@ func BenchmarkParseActorAddress(b *testing.B) {
 	b.Run("secp256k1", benchTestWithAddrs(makeSecpAddresses(20)))
 	b.Run("id", benchTestWithAddrs(makeIDAddresses(20)))
 }
+
+// makeCborAddressArray encodes n addresses of every protocol as a CBOR array,
+// as found in message blocks.
+func makeCborAddressArray(n int) []byte {
+	var raw [][]byte
+	raw = append(raw, makeActorAddresses(n/4)...)
+	raw = append(raw, makeBlsAddresses(int64(n/4))...)
+	raw = append(raw, makeSecpAddresses(n/4)...)
+	raw = append(raw, makeIDAddresses(n-3*(n/4))...)
+
+	buf := new(bytes.Buffer)
+	if err := cbg.WriteMajorTypeHeader(buf, cbg.MajArray, uint64(len(raw))); err != nil {
+		panic(err) // ok
+	}
+	for _, b := range raw {
+		a, err := NewFromBytes(b)
+		if err != nil {
+			panic(err) // ok
+		}
+		if err := a.MarshalCBOR(buf); err != nil {
+			panic(err) // ok
+		}
+	}
+	return buf.Bytes()
+}
+
+func BenchmarkCborUnmarshalArray(b *testing.B) {
+	benchDecode := func(n int, decode func(a *Address, r *bytes.Reader, scratch []byte) error) func(b *testing.B) {
+		data := makeCborAddressArray(n)
+		return func(b *testing.B) {
+			addrs := make([]Address, n)
+			scratch := make([]byte, MaxAddressCBORBytes)
+			r := bytes.NewReader(data)
+
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				r.Reset(data)
+				_, l, err := cbg.CborReadHeaderBuf(r, scratch)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for j := uint64(0); j < l; j++ {
+					if err := decode(&addrs[j], r, scratch); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		}
+	}
+
+	for _, n := range []int{1000, 10000} {
+		b.Run(fmt.Sprintf("UnmarshalCBOR/%d", n), benchDecode(n, func(a *Address, r *bytes.Reader, _ []byte) error {
+			return a.UnmarshalCBOR(r)
+		}))
+		b.Run(fmt.Sprintf("UnmarshalCBORBuf/%d", n), benchDecode(n, func(a *Address, r *bytes.Reader, scratch []byte) error {
+			return a.UnmarshalCBORBuf(r, scratch)
+		}))
+	}
+}
+
+func BenchmarkCborMarshalArray(b *testing.B) {
+	data := makeCborAddressArray(10000)
+	r := bytes.NewReader(data)
+	_, l, err := cbg.CborReadHeader(r)
+	if err != nil {
+		b.Fatal(err)
+	}
+	addrs := make([]Address, l)
+	for i := range addrs {
+		if err := addrs[i].UnmarshalCBOR(r); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	benchEncode := func(encode func(a *Address, w io.Writer, scratch []byte) error) func(b *testing.B) {
+		return func(b *testing.B) {
+			buf := bytes.NewBuffer(make([]byte, 0, len(data)))
+			scratch := make([]byte, MaxAddressCBORBytes)
+
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				if err := cbg.WriteMajorTypeHeaderBuf(scratch, buf, cbg.MajArray, uint64(len(addrs))); err != nil {
+					b.Fatal(err)
+				}
+				for j := range addrs {
+					if err := encode(&addrs[j], buf, scratch); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		}
+	}
+
+	b.Run("MarshalCBOR", benchEncode(func(a *Address, w io.Writer, _ []byte) error {
+		return a.MarshalCBOR(w)
+	}))
+	b.Run("MarshalCBORBuf", benchEncode(func(a *Address, w io.Writer, scratch []byte) error {
+		return a.MarshalCBORBuf(w, scratch)
+	}))
+}
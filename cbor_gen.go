@@ -85,3 +85,79 @@ func (t *SubnetID) UnmarshalCBOR(r io.Reader) error {
 	}
 	return nil
 }
+
+var lengthBufAddressBookEntry = []byte{131}
+
+func (t *AddressBookEntry) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+	if _, err := w.Write(lengthBufAddressBookEntry); err != nil {
+		return err
+	}
+
+	// t.Subnet (address.SubnetID) (struct)
+	if err := t.Subnet.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.Key (address.Address) (struct)
+	if err := t.Key.MarshalCBOR(w); err != nil {
+		return err
+	}
+
+	// t.ID (address.Address) (struct)
+	if err := t.ID.MarshalCBOR(w); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *AddressBookEntry) UnmarshalCBOR(r io.Reader) error {
+	*t = AddressBookEntry{}
+
+	br := cbg.GetPeeker(r)
+	scratch := make([]byte, 8)
+
+	maj, extra, err := cbg.CborReadHeaderBuf(br, scratch)
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+
+	if extra != 3 {
+		return fmt.Errorf("cbor input had wrong number of fields")
+	}
+
+	// t.Subnet (address.SubnetID) (struct)
+
+	{
+
+		if err := t.Subnet.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Subnet: %w", err)
+		}
+
+	}
+	// t.Key (address.Address) (struct)
+
+	{
+
+		if err := t.Key.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.Key: %w", err)
+		}
+
+	}
+	// t.ID (address.Address) (struct)
+
+	{
+
+		if err := t.ID.UnmarshalCBOR(br); err != nil {
+			return xerrors.Errorf("unmarshaling t.ID: %w", err)
+		}
+
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// Package cboratlas registers Address with the refmt atlas of go-ipld-cbor,
+// so that go-ipld-cbor can encode Go types with Address fields, such as
+// through cbor.WrapObject or cbor.DumpObject.
+//
+// The registration used to be done by the address package itself. Programs
+// relying on it should import this package for its side effects:
+//
+//	import _ "github.com/filecoin-project/go-address/cboratlas"
+//
+// Addresses are encoded as CBOR text strings holding their bytes, as done by
+// Address.Marshal. The empty address is encoded as an empty string.
+package cboratlas
+
+import (
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/polydawn/refmt/obj/atlas"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func init() {
+	cbor.RegisterCborType(AddressAtlasEntry)
+}
+
+// AddressAtlasEntry is the refmt atlas entry of Address.
+var AddressAtlasEntry = atlas.BuildEntry(address.Address{}).Transform().
+	TransformMarshal(atlas.MakeMarshalTransformFunc(
+		func(a address.Address) (string, error) {
+			return string(a.Bytes()), nil
+		})).
+	TransformUnmarshal(atlas.MakeUnmarshalTransformFunc(
+		func(x string) (address.Address, error) {
+			return address.NewFromBytes([]byte(x))
+		})).
+	Complete()
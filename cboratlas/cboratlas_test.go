@@ -0,0 +1,94 @@
+package cboratlas_test
+
+import (
+	"testing"
+
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	_ "github.com/filecoin-project/go-address/cboratlas"
+)
+
+func testAddresses(t *testing.T) []address.Address {
+	getter := address.NewForTestGetter()
+	id, err := address.NewIDAddress(1024)
+	require.NoError(t, err)
+	maxID, err := address.NewIDAddress(1<<63 - 1)
+	require.NoError(t, err)
+	secp, err := address.NewSecp256k1Address([]byte("secp256k1 public key"))
+	require.NoError(t, err)
+	bls, err := address.NewBLSAddress(make([]byte, address.BlsPublicKeyBytes))
+	require.NoError(t, err)
+	hc, err := address.NewHCAddress(address.NewSubnetID(address.RootSubnet, id), secp)
+	require.NoError(t, err)
+	deep, err := address.NewHCAddress(address.NewSubnetID(address.NewSubnetID(address.RootSubnet, maxID), maxID), bls)
+	require.NoError(t, err)
+	return []address.Address{address.Undef, id, maxID, secp, getter(), bls, hc, deep}
+}
+
+// Marshal must encode as go-ipld-cbor and the atlas entry do, and Unmarshal
+// must decode its output.
+func TestConformance(t *testing.T) {
+	for _, a := range testAddresses(t) {
+		legacy, err := cbor.DumpObject(a)
+		require.NoError(t, err)
+		b, err := a.Marshal()
+		require.NoError(t, err)
+		require.Equal(t, legacy, b, a.Bytes())
+
+		var x address.Address
+		require.NoError(t, x.Unmarshal(b))
+		require.Equal(t, a, x)
+	}
+
+	// go-ipld-cbor decodes through UnmarshalCBOR, so Unmarshal still
+	// accepts and rejects the same byte strings.
+	for _, in := range [][]byte{
+		{0x42, 0x00, 0x01},
+		{0x40},
+		{0x58, 0x02, 0x00, 0x01},
+		{0x42, 0x09, 0x01},
+		{0xf6},
+		{},
+	} {
+		var legacy, x address.Address
+		legacyErr := cbor.DecodeInto(in, &legacy)
+		require.Equal(t, legacyErr, x.Unmarshal(in), in)
+		require.Equal(t, legacy, x)
+	}
+
+	// Text strings are decoded as the output of Marshal.
+	id1, err := address.NewIDAddress(1)
+	require.NoError(t, err)
+	var x address.Address
+	require.NoError(t, x.Unmarshal([]byte{0x62, 0x00, 0x01}))
+	require.Equal(t, id1, x)
+	for _, in := range [][]byte{
+		{0x62, 0x09, 0x01},
+		{0x62, 0x00, 0x01, 0x00},
+		{0x63, 0x00, 0x01},
+	} {
+		require.Error(t, x.Unmarshal(in), in)
+	}
+}
+
+// Go types with address fields can be encoded by go-ipld-cbor.
+func TestAtlasRoundTrip(t *testing.T) {
+	type message struct {
+		To   address.Address
+		From address.Address
+	}
+	cbor.RegisterCborType(message{})
+
+	addrs := testAddresses(t)
+	for i := 1; i < len(addrs); i++ {
+		msg := message{To: addrs[i-1], From: addrs[i]}
+		b, err := cbor.DumpObject(msg)
+		require.NoError(t, err)
+
+		var out message
+		require.NoError(t, cbor.DecodeInto(b, &out))
+		require.Equal(t, msg, out)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	FormatTSV   = "tsv"
+	FormatJSONL = "jsonl"
+)
+
+var ValidBatchFormats = []string{FormatTSV, FormatJSONL}
+
+// batchResult is the outcome of processing one input line in batch mode.
+type batchResult struct {
+	Line   int         `json:"line"`
+	Input  string      `json:"input"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// tsver is implemented by results that span several TSV columns.
+type tsver interface {
+	tsv() string
+}
+
+// exitError makes fcaddr exit with the given code without printing any
+// further error.
+type exitError struct {
+	code int
+}
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// runBatch calls fn on every non-empty line of r and writes one result per
+// line to w in the given format, going on after failed lines. It returns an
+// exitError after printing a summary to stderr if any line failed.
+func runBatch(r io.Reader, w io.Writer, format string, fn func(line string) (interface{}, error)) error {
+	if format != FormatTSV && format != FormatJSONL {
+		return xerrors.Errorf("invalid batch format '%s' [%s]", format, strings.Join(ValidBatchFormats, ", "))
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	var total, failed int
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		input := strings.TrimSpace(sc.Text())
+		if input == "" {
+			continue
+		}
+		total++
+
+		res := batchResult{Line: n, Input: input}
+		out, err := fn(input)
+		if err != nil {
+			failed++
+			res.Error = err.Error()
+		} else {
+			res.Result = out
+		}
+
+		switch format {
+		case FormatJSONL:
+			if err := enc.Encode(res); err != nil {
+				return err
+			}
+		case FormatTSV:
+			status, value := "ok", ""
+			if res.Error != "" {
+				status, value = "error", res.Error
+			} else if t, ok := out.(tsver); ok {
+				value = t.tsv()
+			} else {
+				value = fmt.Sprint(out)
+			}
+			fmt.Fprintf(bw, "%d\t%s\t%s\t%s\n", n, input, status, value)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "error: %d of %d lines failed\n", failed, total)
+		return exitError{code: 1}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func TestRunBatch(t *testing.T) {
+	defer func(network address.Network) { address.CurrentNetwork = network }(address.CurrentNetwork)
+
+	input := "f01000\n\n  x01000\t\nt01024\nf0x\n"
+	inspect := func(line string) (interface{}, error) {
+		return inspectAddress(line, nil)
+	}
+
+	var out bytes.Buffer
+	err := runBatch(strings.NewReader(input), &out, FormatTSV, inspect)
+	var ee exitError
+	require.True(t, errors.As(err, &ee))
+	require.Equal(t, 1, ee.code)
+	require.Equal(t, strings.Join([]string{
+		"1\tf01000\tok\tf01000\tmainnet\tid\te807\t\t1000\t\t",
+		"3\tx01000\terror\tinvalid address \"x01000\": unknown address network",
+		"4\tt01024\tok\tt01024\ttestnet\tid\t8008\t\t1024\t\t",
+		"5\tf0x\terror\tinvalid address \"f0x\": invalid address payload",
+	}, "\n")+"\n", out.String())
+
+	// Results are also printed as JSON lines, every line is processed.
+	out.Reset()
+	err = runBatch(strings.NewReader(input), &out, FormatJSONL, inspect)
+	require.True(t, errors.As(err, &ee))
+	var results []batchResult
+	sc := bufio.NewScanner(&out)
+	for sc.Scan() {
+		var res batchResult
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &res))
+		results = append(results, res)
+	}
+	require.Len(t, results, 4)
+	for i, line := range []int{1, 3, 4, 5} {
+		require.Equal(t, line, results[i].Line)
+		require.Equal(t, i%2 == 1, results[i].Error != "", results[i])
+		require.Equal(t, i%2 == 0, results[i].Result != nil, results[i])
+	}
+	require.Equal(t, "x01000", results[1].Input)
+
+	// Batches without failures succeed.
+	out.Reset()
+	require.NoError(t, runBatch(strings.NewReader("f01000\nt01024\n"), &out, FormatTSV, inspect))
+	require.Equal(t, 2, strings.Count(out.String(), "\tok\t"))
+
+	// Results that are not TSV rows are printed as a single column.
+	out.Reset()
+	require.NoError(t, runBatch(strings.NewReader("a\nb\n"), &out, FormatTSV, func(line string) (interface{}, error) {
+		return strings.ToUpper(line), nil
+	}))
+	require.Equal(t, "1\ta\tok\tA\n2\tb\tok\tB\n", out.String())
+
+	require.Error(t, runBatch(strings.NewReader(input), &out, "csv", inspect))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func runConvert(args []string) error {
+	var (
+		batch  bool
+		format = FormatTSV
+	)
+
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	fs.BoolVar(&batch, "batch", false, "read one address per line from stdin and print one result per line")
+	fs.StringVar(&format, "format", format, fmt.Sprintf("output format in batch mode [%s]", strings.Join(ValidBatchFormats, ", ")))
+	netName := networkFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s convert [--network <network>] [--batch [--format <format>]] [address]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Re-encodes an address for the other network, or for --network if given.\n")
+		fmt.Fprintf(fs.Output(), "Reads the address from stdin if not given.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	network, err := parseNetwork(*netName)
+	if err != nil {
+		return err
+	}
+
+	if batch {
+		return runBatch(os.Stdin, os.Stdout, format, func(line string) (interface{}, error) {
+			return convertAddress(line, network)
+		})
+	}
+
+	input, err := singleInput(fs)
+	if err != nil {
+		return err
+	}
+
+	out, err := convertAddress(input, network)
+	if err != nil {
+		return err
+	}
+	fmt.Println(out)
+	return nil
+}
+
+// convertAddress re-encodes an address string for the given network, or for
+// the other network if nil. The pretty form of hierarchical addresses is
+// kept.
+func convertAddress(s string, to *address.Network) (string, error) {
+	a, network, err := parseAddress(s)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case to != nil:
+		address.CurrentNetwork = *to
+	case network == address.Mainnet:
+		address.CurrentNetwork = address.Testnet
+	default:
+		address.CurrentNetwork = address.Mainnet
+	}
+
+	if strings.Contains(s, address.HCAddrSeparator) {
+		return a.PrettyPrint(), nil
+	}
+	return a.String(), nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func TestConvertAddress(t *testing.T) {
+	defer func(network address.Network) { address.CurrentNetwork = network }(address.CurrentNetwork)
+
+	mainnet, testnet := address.Mainnet, address.Testnet
+	testCases := []struct {
+		input  string
+		to     *address.Network
+		output string
+	}{
+		// Addresses go to the other network by default.
+		{"f01000", nil, "t01000"},
+		{"t01000", nil, "f01000"},
+		{validSecp, nil, "f" + validSecp[1:]},
+		{"f01000", &mainnet, "f01000"},
+		{"f01000", &testnet, "t01000"},
+		// The pretty form of hierarchical addresses is kept.
+		{"/root/f01:f01000", nil, "/root/t01:t01000"},
+		{"/root:t01000", &mainnet, "/root:f01000"},
+	}
+
+	for _, tc := range testCases {
+		out, err := convertAddress(tc.input, tc.to)
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.output, out, tc.input)
+	}
+
+	// Hierarchical addresses without the pretty form stay encoded.
+	hc, err := address.NewHCAddress(address.RootSubnet, address.TestAddress)
+	require.NoError(t, err)
+	address.CurrentNetwork = address.Mainnet
+	out, err := convertAddress(hc.String(), nil)
+	require.NoError(t, err)
+	address.CurrentNetwork = address.Testnet
+	require.Equal(t, hc.String(), out)
+
+	for _, input := range []string{"", "x01000", "f0x"} {
+		_, err := convertAddress(input, nil)
+		require.Error(t, err, input)
+	}
+}
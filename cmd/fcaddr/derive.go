@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-address/keys"
+)
+
+// PassphraseEnv is the environment variable holding the optional BIP-39
+// passphrase of mnemonics, kept out of the command line.
+const PassphraseEnv = "FCADDR_MNEMONIC_PASSPHRASE"
+
+var ValidPrivateKeyTypes = []string{KTBLS, KTSecp256k1}
+
+// derivedKey is the public part of a private key as printed by derive.
+type derivedKey struct {
+	Type      string `json:"type"`
+	Path      string `json:"path,omitempty"`
+	PublicKey string `json:"publicKey"`
+	Address   string `json:"address"`
+}
+
+func runDerive(args []string) error {
+	var (
+		keyType     string
+		inputFormat = InputAuto
+		mnemonic    bool
+		path        = keys.DefaultDerivationPath
+		asJSON      bool
+	)
+
+	fs := flag.NewFlagSet("derive", flag.ExitOnError)
+	fs.StringVar(&keyType, "type", keyType, fmt.Sprintf("type of private key provided [%s]", strings.Join(ValidPrivateKeyTypes, ", ")))
+	fs.StringVar(&inputFormat, "input-format", inputFormat, fmt.Sprintf("format of the private key provided [%s]", strings.Join(ValidInputFormats, ", ")))
+	fs.BoolVar(&mnemonic, "mnemonic", mnemonic, "read a BIP-39 mnemonic instead of a private key")
+	fs.StringVar(&path, "path", path, "BIP-44 derivation path used with --mnemonic")
+	fs.BoolVar(&asJSON, "json", false, "print the result as JSON")
+	netName := networkFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s derive [--type <key type>] [--input-format <format>] [--network <network>] [--json] < private-key\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "       %s derive --mnemonic [--path <path>] [--network <network>] [--json] < mnemonic\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Prints the public key and address of a private key read from stdin. Mnemonics\n")
+		fmt.Fprintf(fs.Output(), "derive secp256k1 keys, their optional passphrase is read from $%s.\n", PassphraseEnv)
+		fmt.Fprintf(fs.Output(), "Everything runs offline.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	network, err := parseNetwork(*netName)
+	if err != nil {
+		return err
+	}
+	if network != nil {
+		address.CurrentNetwork = *network
+	}
+
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	var (
+		ki  keys.KeyInfo
+		out derivedKey
+	)
+	if mnemonic {
+		if keyType != "" && keyType != KTSecp256k1 {
+			return xerrors.Errorf("mnemonics can only derive %s keys", KTSecp256k1)
+		}
+		priv, err := keys.DeriveFromMnemonic(string(input), os.Getenv(PassphraseEnv), path)
+		if err != nil {
+			return err
+		}
+		ki = keys.KeyInfo{Type: keys.Secp256k1, PrivateKey: priv}
+		out.Path = path
+	} else {
+		priv, lotusKey, err := decodeKeyInput(input, inputFormat)
+		if err != nil {
+			return err
+		}
+		switch {
+		case lotusKey != nil:
+			if keyType != "" && keyType != lotusKey.Type {
+				return xerrors.Errorf("key type '%s' does not match the exported key type '%s'", keyType, lotusKey.Type)
+			}
+			ki = *lotusKey
+		case keyType == KTSecp256k1 || keyType == KTBLS:
+			ki = keys.KeyInfo{Type: keyType, PrivateKey: priv}
+		default:
+			return xerrors.Errorf("--type must be one of [%s]", strings.Join(ValidPrivateKeyTypes, ", "))
+		}
+	}
+
+	pub, err := ki.PublicKey()
+	if err != nil {
+		return err
+	}
+	addr, err := keys.Address(ki.Type, pub)
+	if err != nil {
+		return err
+	}
+
+	out.Type = ki.Type
+	out.PublicKey = hex.EncodeToString(pub)
+	out.Address = addr.String()
+
+	if asJSON {
+		return json.NewEncoder(os.Stdout).Encode(out)
+	}
+	fmt.Printf("type:       %s\n", out.Type)
+	if out.Path != "" {
+		fmt.Printf("path:       %s\n", out.Path)
+	}
+	fmt.Printf("public key: %s\n", out.PublicKey)
+	fmt.Printf("address:    %s\n", out.Address)
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address/keys"
+)
+
+const (
+	InputAuto     = "auto"
+	InputHex      = "hex"
+	InputBase64   = "base64"
+	InputRaw      = "raw"
+	InputLotusKey = "lotus-key"
+)
+
+var ValidInputFormats = []string{InputAuto, InputHex, InputBase64, InputRaw, InputLotusKey}
+
+func isSupportedInputFormat(format string) bool {
+	for _, valid := range ValidInputFormats {
+		if valid == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+// detectInputFormat guesses the format of a key read from the input.
+//
+// Input that is not printable text is raw binary. Otherwise hex is preferred
+// over base64, and hex-encoded JSON objects are Lotus key exports.
+func detectInputFormat(in []byte) (string, error) {
+	if !isText(in) {
+		return InputRaw, nil
+	}
+	s := strings.TrimSpace(string(in))
+	if b, err := hex.DecodeString(s); err == nil {
+		if bytes.HasPrefix(b, []byte("{")) && json.Valid(b) {
+			return InputLotusKey, nil
+		}
+		return InputHex, nil
+	}
+	// only padded base64 is detected, as too many short strings are valid
+	// unpadded base64.
+	if _, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return InputBase64, nil
+	}
+	return "", xerrors.Errorf("could not detect input format, use --input-format")
+}
+
+// decodeKeyInput decodes a key in the given input format. Lotus key exports
+// are returned as a KeyInfo, any other format as the key bytes.
+func decodeKeyInput(in []byte, format string) ([]byte, *keys.KeyInfo, error) {
+	if format == InputAuto {
+		var err error
+		if format, err = detectInputFormat(in); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	switch format {
+	case InputRaw:
+		return in, nil, nil
+	case InputHex:
+		b, err := hex.DecodeString(strings.TrimSpace(string(in)))
+		return b, nil, err
+	case InputBase64:
+		b, err := decodeBase64(strings.TrimSpace(string(in)))
+		return b, nil, err
+	case InputLotusKey:
+		ki, err := keys.DecodeLotusKey(string(in))
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, &ki, nil
+	default:
+		return nil, nil, xerrors.Errorf("invalid input format '%s'", format)
+	}
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+// isText returns true if b only holds printable ASCII characters and
+// whitespace.
+func isText(b []byte) bool {
+	for _, c := range b {
+		if (c < ' ' || c > '~') && c != '\t' && c != '\n' && c != '\r' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address/keys"
+)
+
+func TestDetectInputFormat(t *testing.T) {
+	key := []byte{0x04, 0xde, 0xad, 0xbe, 0xef, 0x00, 0x01, 0x02, 0xff}
+	export, err := keys.KeyInfo{Type: KTSecp256k1, PrivateKey: make([]byte, 32)}.ExportLotusKey()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		input  string
+		format string
+	}{
+		{hex.EncodeToString(key), InputHex},
+		{hex.EncodeToString(key) + "\n", InputHex},
+		{"  DEADBEEF  ", InputHex},
+		{base64.StdEncoding.EncodeToString(key), InputBase64},
+		{base64.StdEncoding.EncodeToString(key) + "\n", InputBase64},
+		{string(key), InputRaw},
+		{export, InputLotusKey},
+		{export + "\n", InputLotusKey},
+		// Hex that does not hold a JSON object is a plain key.
+		{hex.EncodeToString([]byte("{not json")), InputHex},
+	}
+
+	for _, tc := range testCases {
+		format, err := detectInputFormat([]byte(tc.input))
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.format, format, tc.input)
+	}
+
+	// Unpadded base64 is too ambiguous to be detected.
+	for _, input := range []string{base64.RawStdEncoding.EncodeToString(key[:8]), "not a key"} {
+		_, err := detectInputFormat([]byte(input))
+		require.Error(t, err, input)
+	}
+}
+
+func TestDecodeKeyInput(t *testing.T) {
+	key := []byte{0x04, 0xde, 0xad, 0xbe, 0xef, 0x00, 0x01, 0x02, 0xff}
+	ki := keys.KeyInfo{Type: KTSecp256k1, PrivateKey: make([]byte, 32)}
+	export, err := ki.ExportLotusKey()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		input  string
+		format string
+	}{
+		{hex.EncodeToString(key), InputAuto},
+		{hex.EncodeToString(key) + "\n", InputHex},
+		{base64.StdEncoding.EncodeToString(key), InputAuto},
+		{base64.RawStdEncoding.EncodeToString(key), InputBase64},
+		{string(key), InputAuto},
+		{string(key), InputRaw},
+	}
+	for _, tc := range testCases {
+		b, decoded, err := decodeKeyInput([]byte(tc.input), tc.format)
+		require.NoError(t, err, tc.input)
+		require.Nil(t, decoded)
+		require.Equal(t, key, b, tc.input)
+	}
+
+	for _, format := range []string{InputAuto, InputLotusKey} {
+		b, decoded, err := decodeKeyInput([]byte(export), format)
+		require.NoError(t, err)
+		require.Nil(t, b)
+		require.Equal(t, &ki, decoded)
+	}
+
+	// Explicit formats are not guessed.
+	_, _, err = decodeKeyInput([]byte(hex.EncodeToString(key)), InputLotusKey)
+	require.Error(t, err)
+	_, _, err = decodeKeyInput([]byte("zz"), InputHex)
+	require.Error(t, err)
+	_, _, err = decodeKeyInput(key, "pem")
+	require.Error(t, err)
+	require.False(t, isSupportedInputFormat("pem"))
+	require.True(t, isSupportedInputFormat(InputLotusKey))
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+)
+
+var protocolNames = map[address.Protocol]string{
+	address.ID:           "id",
+	address.SECP256K1:    "secp256k1",
+	address.Actor:        "actor",
+	address.BLS:          "bls",
+	address.Hierarchical: "hierarchical",
+}
+
+// addressInfo holds the components of an address as printed by inspect.
+type addressInfo struct {
+	Address  string  `json:"address"`
+	Network  string  `json:"network"`
+	Protocol string  `json:"protocol"`
+	Payload  string  `json:"payload"`
+	Checksum string  `json:"checksum,omitempty"`
+	ID       *uint64 `json:"id,omitempty"`
+	Subnet   string  `json:"subnet,omitempty"`
+	RawAddr  string  `json:"rawAddress,omitempty"`
+}
+
+func runInspect(args []string) error {
+	var (
+		asJSON bool
+		batch  bool
+		format = FormatTSV
+	)
+
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	fs.BoolVar(&asJSON, "json", false, "print the result as JSON")
+	fs.BoolVar(&batch, "batch", false, "read one address per line from stdin and print one result per line")
+	fs.StringVar(&format, "format", format, fmt.Sprintf("output format in batch mode [%s], --json implies jsonl", strings.Join(ValidBatchFormats, ", ")))
+	netName := networkFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s inspect [--json] [--batch [--format <format>]] [address]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Reads the address from stdin if not given. Hierarchical addresses\n")
+		fmt.Fprintf(fs.Output(), "can also be given in their pretty form (<subnet>%s<address>).\n", address.HCAddrSeparator)
+		fmt.Fprintf(fs.Output(), "Addresses are printed for the network of the input unless --network is given.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	network, err := parseNetwork(*netName)
+	if err != nil {
+		return err
+	}
+
+	if batch {
+		if asJSON {
+			format = FormatJSONL
+		}
+		return runBatch(os.Stdin, os.Stdout, format, func(line string) (interface{}, error) {
+			return inspectAddress(line, network)
+		})
+	}
+
+	input, err := singleInput(fs)
+	if err != nil {
+		return err
+	}
+
+	info, err := inspectAddress(input, network)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return json.NewEncoder(os.Stdout).Encode(info)
+	}
+	printAddressInfo(os.Stdout, info)
+	return nil
+}
+
+// parseAddress parses an address string with Address.UnmarshalText, which
+// accepts the pretty form of hierarchical addresses. It returns the network
+// the address was written for.
+func parseAddress(s string) (address.Address, address.Network, error) {
+	var a address.Address
+	if err := a.UnmarshalText([]byte(s)); err != nil {
+		return address.Undef, 0, err
+	}
+	if a == address.Undef {
+		return address.Undef, 0, xerrors.Errorf("empty address")
+	}
+
+	// The network is the one of the raw address in the pretty form.
+	network, err := networkFromString(s[strings.LastIndex(s, address.HCAddrSeparator)+1:])
+	if err != nil {
+		return address.Undef, 0, err
+	}
+	return a, network, nil
+}
+
+// inspectAddress returns the components of an address. They are rendered
+// for the given network, or the network of the input if nil.
+func inspectAddress(s string, out *address.Network) (*addressInfo, error) {
+	a, network, err := parseAddress(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if out != nil {
+		network = *out
+	}
+	address.CurrentNetwork = network
+
+	info := &addressInfo{
+		Address:  a.String(),
+		Network:  networkNames[network],
+		Protocol: protocolNames[a.Protocol()],
+		Payload:  hex.EncodeToString(a.Payload()),
+	}
+
+	switch a.Protocol() {
+	case address.ID:
+		id, err := address.IDFromAddress(a)
+		if err != nil {
+			return nil, err
+		}
+		info.ID = &id
+	case address.Hierarchical:
+		sn, err := a.Subnet()
+		if err != nil {
+			return nil, err
+		}
+		raw, err := a.RawAddr()
+		if err != nil {
+			return nil, err
+		}
+		info.Subnet = sn.String()
+		info.RawAddr = raw.String()
+		info.Checksum = hex.EncodeToString(address.Checksum(a.Bytes()))
+	default:
+		info.Checksum = hex.EncodeToString(address.Checksum(a.Bytes()))
+	}
+
+	return info, nil
+}
+
+func (info *addressInfo) tsv() string {
+	var id string
+	if info.ID != nil {
+		id = fmt.Sprint(*info.ID)
+	}
+	return strings.Join([]string{
+		info.Address, info.Network, info.Protocol, info.Payload,
+		info.Checksum, id, info.Subnet, info.RawAddr,
+	}, "\t")
+}
+
+func printAddressInfo(w io.Writer, info *addressInfo) {
+	fmt.Fprintf(w, "address:  %s\n", info.Address)
+	fmt.Fprintf(w, "network:  %s\n", info.Network)
+	fmt.Fprintf(w, "protocol: %s\n", info.Protocol)
+	fmt.Fprintf(w, "payload:  %s\n", info.Payload)
+	if info.Checksum != "" {
+		fmt.Fprintf(w, "checksum: %s\n", info.Checksum)
+	}
+	if info.ID != nil {
+		fmt.Fprintf(w, "id:       %d\n", *info.ID)
+	}
+	if info.Subnet != "" {
+		fmt.Fprintf(w, "subnet:   %s\n", info.Subnet)
+		fmt.Fprintf(w, "raw:      %s\n", info.RawAddr)
+	}
+}
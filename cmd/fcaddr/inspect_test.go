@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func TestParseAddress(t *testing.T) {
+	testCases := []struct {
+		input   string
+		bytes   string
+		network address.Network
+	}{
+		{"f01000", "\x00\xe8\x07", address.Mainnet},
+		{"t01000", "\x00\xe8\x07", address.Testnet},
+		{"/root:f01000", "\x04\x05\x03/root\x00\xe8\x07", address.Mainnet},
+		{"/root/f01:t01000", "\x04\x09\x03/root/f01\x00\xe8\x07", address.Testnet},
+	}
+
+	for _, tc := range testCases {
+		a, network, err := parseAddress(tc.input)
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.network, network, tc.input)
+		require.Equal(t, []byte(tc.bytes), a.Bytes()[:len(tc.bytes)], tc.input)
+	}
+
+	for _, input := range []string{"", address.UndefAddressString, "x01000", "f0x", "/other:f01000", "/root:x01000", "/root:/root:f01000"} {
+		_, _, err := parseAddress(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestInspectAddress(t *testing.T) {
+	defer func(network address.Network) { address.CurrentNetwork = network }(address.CurrentNetwork)
+
+	info, err := inspectAddress("f01000", nil)
+	require.NoError(t, err)
+	id := uint64(1000)
+	require.Equal(t, &addressInfo{
+		Address:  "f01000",
+		Network:  NetMainnet,
+		Protocol: "id",
+		Payload:  "e807",
+		ID:       &id,
+	}, info)
+
+	// Addresses are printed for the requested network.
+	testnet := address.Testnet
+	info, err = inspectAddress("/root:f01000", &testnet)
+	require.NoError(t, err)
+	require.Equal(t, "hierarchical", info.Protocol)
+	require.Equal(t, NetTestnet, info.Network)
+	require.Equal(t, "/root", info.Subnet)
+	require.Equal(t, "t01000", info.RawAddr)
+	require.NotEmpty(t, info.Checksum)
+	require.Nil(t, info.ID)
+
+	info, err = inspectAddress(validSecp, nil)
+	require.NoError(t, err)
+	require.Equal(t, "secp256k1", info.Protocol)
+	require.Equal(t, validSecp, info.Address)
+	require.Len(t, info.Payload, 2*address.PayloadHashLength)
+	require.Len(t, info.Checksum, 2*address.ChecksumHashLength)
+}
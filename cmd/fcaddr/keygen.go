@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-address/keys"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatLotusKey = "lotus-key"
+)
+
+var ValidKeygenFormats = []string{FormatJSON, FormatLotusKey}
+
+// generatedKey is a key pair as printed by keygen and vanity. Actor
+// addresses found by vanity come with their seed instead.
+type generatedKey struct {
+	Type       string `json:"type"`
+	PrivateKey string `json:"privateKey,omitempty"`
+	PublicKey  string `json:"publicKey,omitempty"`
+	Seed       string `json:"seed,omitempty"`
+	Address    string `json:"address"`
+}
+
+func runKeygen(args []string) error {
+	var (
+		keyType string
+		count   = 1
+		seed    string
+		format  = FormatJSON
+	)
+
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	fs.StringVar(&keyType, "type", keyType, fmt.Sprintf("type of key to generate [%s]", strings.Join(ValidPrivateKeyTypes, ", ")))
+	fs.IntVar(&count, "count", count, "number of keys to generate")
+	fs.StringVar(&seed, "seed", seed, "seed for reproducible keys, for tests and devnets only")
+	fs.StringVar(&format, "format", format, fmt.Sprintf("output format [%s]", strings.Join(ValidKeygenFormats, ", ")))
+	netName := networkFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s keygen --type <key type> [--count <n>] [--seed <seed>] [--format <format>] [--network <network>]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Generates key pairs offline and prints one per line, as a JSON object or in\n")
+		fmt.Fprintf(fs.Output(), "the format of `lotus wallet export` with the address and public key on stderr.\n")
+		fmt.Fprintf(fs.Output(), "Keys come from crypto/rand unless --seed is given, in which case the same\n")
+		fmt.Fprintf(fs.Output(), "seed always generates the same keys.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if keyType == "" || fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if keyType != KTSecp256k1 && keyType != KTBLS {
+		return xerrors.Errorf("invalid key type provided '%s'", keyType)
+	}
+	if format != FormatJSON && format != FormatLotusKey {
+		return xerrors.Errorf("invalid format '%s' [%s]", format, strings.Join(ValidKeygenFormats, ", "))
+	}
+	if count < 1 {
+		return xerrors.Errorf("--count must be positive")
+	}
+
+	network, err := parseNetwork(*netName)
+	if err != nil {
+		return err
+	}
+	if network != nil {
+		address.CurrentNetwork = *network
+	}
+
+	var r io.Reader = rand.Reader
+	if seed != "" {
+		r = keys.NewSeededReader([]byte(seed))
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	for i := 0; i < count; i++ {
+		priv, err := keys.Generate(keyType, r)
+		if err != nil {
+			return err
+		}
+		ki := keys.KeyInfo{Type: keyType, PrivateKey: priv}
+		pub, err := ki.PublicKey()
+		if err != nil {
+			return err
+		}
+		addr, err := keys.Address(keyType, pub)
+		if err != nil {
+			return err
+		}
+
+		if format == FormatLotusKey {
+			if err := writeLotusKey(os.Stdout, os.Stderr, ki, pub, addr); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := enc.Encode(generatedKey{
+			Type:       keyType,
+			PrivateKey: hex.EncodeToString(priv),
+			PublicKey:  hex.EncodeToString(pub),
+			Address:    addr.String(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLotusKey writes a key to w in the format of `lotus wallet export`, and
+// its address and public key to info. Only the key goes to w, so that it can
+// be piped to `lotus wallet import`.
+func writeLotusKey(w, info io.Writer, ki keys.KeyInfo, pub []byte, addr address.Address) error {
+	export, err := ki.ExportLotusKey()
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(info, "address: %s public key: %s\n", addr, hex.EncodeToString(pub)); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, export)
+	return err
+}
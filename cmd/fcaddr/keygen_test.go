@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address/keys"
+)
+
+func TestWriteLotusKey(t *testing.T) {
+	priv, err := keys.Generate(KTSecp256k1, keys.NewSeededReader([]byte("seed")))
+	require.NoError(t, err)
+	ki := keys.KeyInfo{Type: KTSecp256k1, PrivateKey: priv}
+	pub, err := ki.PublicKey()
+	require.NoError(t, err)
+	addr, err := keys.Address(KTSecp256k1, pub)
+	require.NoError(t, err)
+
+	var out, info bytes.Buffer
+	require.NoError(t, writeLotusKey(&out, &info, ki, pub, addr))
+
+	// Only the key goes to the output, so it can be imported as is.
+	decoded, err := keys.DecodeLotusKey(strings.TrimSpace(out.String()))
+	require.NoError(t, err)
+	require.Equal(t, ki, decoded)
+
+	require.Equal(t, "address: "+addr.String()+" public key: "+hex.EncodeToString(pub)+"\n", info.String())
+}
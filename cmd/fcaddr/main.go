@@ -1,11 +1,11 @@
 package main
 
 import (
-	"encoding/hex"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	"golang.org/x/xerrors"
@@ -14,49 +14,180 @@ import (
 )
 
 const (
-	KTBLS       = "bls"
-	KTSecp256k1 = "secp256k1"
+	KTBLS          = "bls"
+	KTSecp256k1    = "secp256k1"
+	KTID           = "id"
+	KTActor        = "actor"
+	KTHierarchical = "hierarchical"
 )
 
-var ValidKeyTypes = []string{KTBLS, KTSecp256k1}
+var ValidKeyTypes = []string{KTBLS, KTSecp256k1, KTID, KTActor, KTHierarchical}
 
-// fcaddr reads a base16 encoded public key from stdin and prints a human readable address as output
+// fcaddr reads a base16 encoded public key from stdin and prints a human readable address as output.
+// In batch mode it reads one public key per line instead.
+//
+// Keys can also be given in base64, as raw binary or as a Lotus wallet export
+// (see --input-format). The public key and address of Lotus exports are
+// derived locally from their private key.
+//
+// Other types of addresses are built from a different input:
+//   - id: the decimal actor ID.
+//   - actor: the base16 encoded data the actor address is derived from.
+//   - hierarchical: the address to scope to the subnet given with --subnet.
+//
+// It also provides subcommands to work with existing addresses and subnets:
+//
+//	fcaddr inspect [--json] [--batch] [--network <network>] <address>
+//	fcaddr convert [--batch] [--network <network>] <address>
+//	fcaddr subnet <parent|common-parent|up|down> <subnet>...
+//	fcaddr derive [--type <key type>] [--mnemonic [--path <path>]] < private-key
+//	fcaddr keygen --type <key type> [--count <n>] [--seed <seed>] [--format <format>]
+//	fcaddr vanity --type <type> [--prefix <prefix>] [--suffix <suffix>]
+//	fcaddr validate [--scan] [--format <format>] [address...]
+//	fcaddr serve [--listen <host:port>]
+
+// command is an fcaddr subcommand.
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"inspect", "print the components of an address", runInspect},
+	{"convert", "re-encode an address for another network", runConvert},
+	{"subnet", "route between subnets", runSubnet},
+	{"derive", "derive the address of a private key or mnemonic", runDerive},
+	{"keygen", "generate key pairs offline", runKeygen},
+	{"vanity", "search for an address with a chosen prefix or suffix", runVanity},
+	{"validate", "check addresses and suggest corrections", runValidate},
+	{"serve", "serve the commands as a JSON API", runServe},
+}
 
 func main() {
-	var keyType string
+	if err := run(os.Args[1:]); err != nil {
+		var ee exitError
+		if xerrors.As(err, &ee) {
+			os.Exit(ee.code)
+		}
+		fmt.Printf("error: %s\n", err)
+		os.Exit(1)
+	}
+}
 
+func run(args []string) error {
+	if len(args) > 0 {
+		for _, cmd := range commands {
+			if args[0] == cmd.name {
+				return cmd.run(args[1:])
+			}
+		}
+	}
+	return runPublicKey(args)
+}
+
+// runPublicKey prints the address of the public key read from stdin.
+func runPublicKey(args []string) error {
+	var (
+		keyType     string
+		subnetStr   string
+		inputFormat = InputAuto
+		batch       bool
+		format      = FormatTSV
+	)
+
+	flag.Usage = usage
 	flag.StringVar(&keyType, "type", keyType, fmt.Sprintf("type of public key provided [%s]", strings.Join(ValidKeyTypes, ", ")))
-	flag.Parse()
+	flag.StringVar(&subnetStr, "subnet", subnetStr, "subnet of hierarchical addresses")
+	flag.StringVar(&inputFormat, "input-format", inputFormat, fmt.Sprintf("format of the key provided [%s]", strings.Join(ValidInputFormats, ", ")))
+	flag.BoolVar(&batch, "batch", batch, "read one public key per line and print one result per line")
+	flag.StringVar(&format, "format", format, fmt.Sprintf("output format in batch mode [%s]", strings.Join(ValidBatchFormats, ", ")))
+	netName := networkFlag(flag.CommandLine)
+	_ = flag.CommandLine.Parse(args)
 
-	if len(keyType) == 0 {
+	// the type of Lotus key exports comes with the key.
+	if len(keyType) == 0 && inputFormat != InputLotusKey {
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	if !isSupportedKeyType(keyType) {
-		fmt.Printf("error: invalid key type provided '%s'", keyType)
-		os.Exit(1)
+	if len(keyType) != 0 && !isSupportedKeyType(keyType) {
+		return xerrors.Errorf("invalid key type provided '%s'", keyType)
 	}
 
-	publicKeyHex, err := io.ReadAll(os.Stdin)
+	if !isSupportedInputFormat(inputFormat) {
+		return xerrors.Errorf("invalid input format provided '%s'", inputFormat)
+	}
+	if batch && inputFormat == InputRaw {
+		return xerrors.Errorf("raw input cannot be used in batch mode")
+	}
+
+	network, err := parseNetwork(*netName)
 	if err != nil {
-		fmt.Printf("error: %s\n", err)
-		os.Exit(1)
+		return err
+	}
+	if network != nil {
+		address.CurrentNetwork = *network
+	}
+
+	subnet := address.UndefSubnetID
+	if keyType == KTHierarchical {
+		if subnetStr == "" {
+			return xerrors.Errorf("--subnet is required for hierarchical addresses")
+		}
+		if subnet, err = address.ParseSubnetID(subnetStr); err != nil {
+			return err
+		}
 	}
 
-	publicKeyBytes, err := hex.DecodeString(strings.TrimSpace(string(publicKeyHex)))
+	if batch {
+		return runBatch(os.Stdin, os.Stdout, format, func(line string) (interface{}, error) {
+			return addrFromInputByType([]byte(line), keyType, inputFormat, subnet)
+		})
+	}
+
+	input, err := io.ReadAll(os.Stdin)
 	if err != nil {
-		fmt.Printf("error: %s\n", err)
-		os.Exit(1)
+		return err
 	}
 
-	addr, err := addrFromPubicKeyByType(publicKeyBytes, keyType)
+	addr, err := addrFromInputByType(input, keyType, inputFormat, subnet)
 	if err != nil {
-		fmt.Printf("error: %s\n", err)
-		os.Exit(1)
+		return err
 	}
 
 	fmt.Printf("%s\n", addr)
+	return nil
+}
+
+// singleInput returns the only positional argument of fs, or the contents of
+// stdin if there is none.
+func singleInput(fs *flag.FlagSet) (string, error) {
+	switch fs.NArg() {
+	case 0:
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	case 1:
+		return strings.TrimSpace(fs.Arg(0)), nil
+	default:
+		fs.Usage()
+		os.Exit(1)
+		return "", nil
+	}
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s --type <key type> [--subnet <subnet>] [--input-format <format>] [--network <network>] [--batch] < public-key\n", os.Args[0])
+	fmt.Fprintf(out, "       %s <command> [arguments]\n\n", os.Args[0])
+	flag.PrintDefaults()
+	fmt.Fprintf(out, "\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-10s %s\n", cmd.name, cmd.usage)
+	}
 }
 
 func isSupportedKeyType(keyType string) bool {
@@ -69,6 +200,42 @@ func isSupportedKeyType(keyType string) bool {
 	return false
 }
 
+// addrFromInputByType builds an address of the given type from its input
+// (see main).
+func addrFromInputByType(in []byte, keyType string, inputFormat string, subnet address.SubnetID) (address.Address, error) {
+	input := strings.TrimSpace(string(in))
+	switch keyType {
+	case KTID:
+		id, err := strconv.ParseUint(input, 10, 64)
+		if err != nil {
+			return address.Undef, xerrors.Errorf("parsing ID: %w", err)
+		}
+		return address.NewIDAddress(id)
+	case KTHierarchical:
+		raw, _, err := parseAddress(input)
+		if err != nil {
+			return address.Undef, err
+		}
+		addr, err := address.NewHCAddress(subnet, raw)
+		if err != nil {
+			return address.Undef, xerrors.Errorf("converting to hierarchical address: %w", err)
+		}
+		return addr, nil
+	default:
+		publicKeyBytes, ki, err := decodeKeyInput(in, inputFormat)
+		if err != nil {
+			return address.Undef, err
+		}
+		if ki != nil {
+			if keyType != "" && keyType != ki.Type {
+				return address.Undef, xerrors.Errorf("key type '%s' does not match the exported key type '%s'", keyType, ki.Type)
+			}
+			return ki.Address()
+		}
+		return addrFromPubicKeyByType(publicKeyBytes, keyType)
+	}
+}
+
 func addrFromPubicKeyByType(publicKey []byte, keyType string) (address.Address, error) {
 	var addr address.Address
 	var err error
@@ -84,6 +251,11 @@ func addrFromPubicKeyByType(publicKey []byte, keyType string) (address.Address,
 		if err != nil {
 			return address.Undef, xerrors.Errorf("converting BLS to address: %w", err)
 		}
+	case KTActor:
+		addr, err = address.NewActorAddress(publicKey)
+		if err != nil {
+			return address.Undef, xerrors.Errorf("converting data to actor address: %w", err)
+		}
 	default:
 		return address.Undef, xerrors.Errorf("unknown key type")
 	}
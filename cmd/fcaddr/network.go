@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+)
+
+const (
+	NetMainnet = "mainnet"
+	NetTestnet = "testnet"
+)
+
+var ValidNetworks = []string{NetMainnet, NetTestnet}
+
+var networkNames = map[address.Network]string{
+	address.Mainnet: NetMainnet,
+	address.Testnet: NetTestnet,
+}
+
+// networkFlag registers the --network flag on fs.
+func networkFlag(fs *flag.FlagSet) *string {
+	return fs.String("network", "", fmt.Sprintf("network to print addresses for [%s]", strings.Join(ValidNetworks, ", ")))
+}
+
+// parseNetwork returns the network for a --network flag value. It returns
+// nil if no network was given.
+func parseNetwork(name string) (*address.Network, error) {
+	var network address.Network
+	switch name {
+	case "":
+		return nil, nil
+	case NetMainnet:
+		network = address.Mainnet
+	case NetTestnet:
+		network = address.Testnet
+	default:
+		return nil, xerrors.Errorf("invalid network '%s' [%s]", name, strings.Join(ValidNetworks, ", "))
+	}
+	return &network, nil
+}
+
+// networkFromString returns the network of an address string from its prefix.
+func networkFromString(s string) (address.Network, error) {
+	switch {
+	case strings.HasPrefix(s, address.MainnetPrefix):
+		return address.Mainnet, nil
+	case strings.HasPrefix(s, address.TestnetPrefix):
+		return address.Testnet, nil
+	default:
+		return 0, address.ErrUnknownNetwork
+	}
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// openAPI describes the endpoints of fcaddr serve.
+//
+//go:embed openapi.json
+var openAPI []byte
+
+const (
+	DefaultListen      = "localhost:8080"
+	DefaultMaxBody     = 64 << 10
+	DefaultMaxInflight = 64
+	DefaultTimeout     = 10 * time.Second
+)
+
+func runServe(args []string) error {
+	var (
+		listen      = DefaultListen
+		maxBody     = int64(DefaultMaxBody)
+		maxInflight = DefaultMaxInflight
+		timeout     = DefaultTimeout
+	)
+
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.StringVar(&listen, "listen", listen, "address to listen on")
+	fs.Int64Var(&maxBody, "max-body", maxBody, "maximum size of request bodies in bytes")
+	fs.IntVar(&maxInflight, "max-inflight", maxInflight, "maximum number of requests handled at once, others are rejected with 503")
+	fs.DurationVar(&timeout, "timeout", timeout, "timeout to read a request and write its response")
+	netName := networkFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s serve [--listen <host:port>] [--network <network>]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Serves the fcaddr commands as a JSON API, described by GET /openapi.json.\n")
+		fmt.Fprintf(fs.Output(), "Addresses are encoded for --network unless a request sets its own.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 0 || maxBody <= 0 || maxInflight <= 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	network, err := parseNetwork(*netName)
+	if err != nil {
+		return err
+	}
+	s := &server{
+		network:  address.CurrentNetwork,
+		maxBody:  maxBody,
+		inflight: make(chan struct{}, maxInflight),
+	}
+	if network != nil {
+		s.network = *network
+	}
+
+	srv := &http.Server{
+		Addr:              listen,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: timeout,
+		ReadTimeout:       timeout,
+		WriteTimeout:      timeout,
+		IdleTimeout:       6 * timeout,
+		MaxHeaderBytes:    16 << 10,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("listening on %s", listen)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// server serves the fcaddr API.
+type server struct {
+	// mu serializes requests, as addresses are encoded for the global
+	// address.CurrentNetwork.
+	mu       sync.Mutex
+	network  address.Network
+	maxBody  int64
+	inflight chan struct{}
+}
+
+// endpoint handles a request, calling decode to read its JSON body.
+type endpoint func(decode func(v interface{}) error) (interface{}, error)
+
+// httpError is an error with its HTTP status.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, xerrors.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(openAPI)
+	})
+	mux.Handle("/v1/decode", s.handle(s.decode))
+	mux.Handle("/v1/encode", s.handle(s.encode))
+	mux.Handle("/v1/convert", s.handle(s.convert))
+	mux.Handle("/v1/derive", s.handle(s.derive))
+	mux.Handle("/v1/checksum", s.handle(s.checksum))
+	for _, cmd := range subnetCommands {
+		mux.Handle("/v1/subnet/"+cmd.name, s.handle(s.subnet(cmd)))
+	}
+	return mux
+}
+
+// handle serves fn for POST requests with a JSON body, writing its result or
+// error as JSON.
+func (s *server) handle(fn endpoint) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, xerrors.Errorf("method %s not allowed", r.Method))
+			return
+		}
+
+		select {
+		case s.inflight <- struct{}{}:
+			defer func() { <-s.inflight }()
+		default:
+			writeError(w, http.StatusServiceUnavailable, xerrors.Errorf("too many requests"))
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, s.maxBody+1))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, xerrors.Errorf("reading request: %w", err))
+			return
+		}
+		if int64(len(body)) > s.maxBody {
+			writeError(w, http.StatusRequestEntityTooLarge, xerrors.Errorf("request body larger than %d bytes", s.maxBody))
+			return
+		}
+		decode := func(v interface{}) error {
+			dec := json.NewDecoder(bytes.NewReader(body))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(v); err != nil {
+				return &httpError{http.StatusBadRequest, xerrors.Errorf("decoding request: %w", err)}
+			}
+			return nil
+		}
+
+		s.mu.Lock()
+		network := address.CurrentNetwork
+		address.CurrentNetwork = s.network
+		res, err := fn(decode)
+		address.CurrentNetwork = network
+		s.mu.Unlock()
+
+		if err != nil {
+			var he *httpError
+			if xerrors.As(err, &he) {
+				writeError(w, he.status, he.err)
+			} else {
+				writeError(w, http.StatusUnprocessableEntity, err)
+			}
+			return
+		}
+		writeJSON(w, http.StatusOK, res)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
+
+// requestNetwork returns the network set by a request, or nil if none.
+func requestNetwork(name string) (*address.Network, error) {
+	network, err := parseNetwork(name)
+	if err != nil {
+		return nil, &httpError{http.StatusBadRequest, err}
+	}
+	return network, nil
+}
+
+type addressRequest struct {
+	Address string `json:"address"`
+	Network string `json:"network,omitempty"`
+}
+
+type addressResponse struct {
+	Address string `json:"address"`
+}
+
+func (s *server) decode(decode func(v interface{}) error) (interface{}, error) {
+	var req addressRequest
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	network, err := requestNetwork(req.Network)
+	if err != nil {
+		return nil, err
+	}
+	return inspectAddress(req.Address, network)
+}
+
+func (s *server) convert(decode func(v interface{}) error) (interface{}, error) {
+	var req addressRequest
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	network, err := requestNetwork(req.Network)
+	if err != nil {
+		return nil, err
+	}
+	out, err := convertAddress(req.Address, network)
+	if err != nil {
+		return nil, err
+	}
+	return addressResponse{out}, nil
+}
+
+type encodeRequest struct {
+	Protocol string  `json:"protocol"`
+	Payload  string  `json:"payload,omitempty"`
+	ID       *uint64 `json:"id,omitempty"`
+	Network  string  `json:"network,omitempty"`
+}
+
+type encodeResponse struct {
+	Address string `json:"address"`
+	Bytes   string `json:"bytes"`
+}
+
+func (s *server) encode(decode func(v interface{}) error) (interface{}, error) {
+	var req encodeRequest
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	network, err := requestNetwork(req.Network)
+	if err != nil {
+		return nil, err
+	}
+	if network != nil {
+		address.CurrentNetwork = *network
+	}
+
+	var a address.Address
+	switch {
+	case req.Protocol == KTID && req.ID != nil:
+		if a, err = address.NewIDAddress(*req.ID); err != nil {
+			return nil, err
+		}
+	case req.ID != nil:
+		return nil, &httpError{http.StatusBadRequest, xerrors.Errorf("id is only valid for the id protocol")}
+	default:
+		protocol, ok := protocolByName(req.Protocol)
+		if !ok {
+			return nil, &httpError{http.StatusBadRequest, xerrors.Errorf("unknown protocol '%s'", req.Protocol)}
+		}
+		payload, err := hex.DecodeString(req.Payload)
+		if err != nil {
+			return nil, &httpError{http.StatusBadRequest, xerrors.Errorf("decoding payload: %w", err)}
+		}
+		if a, err = address.NewFromBytes(append([]byte{protocol}, payload...)); err != nil {
+			return nil, err
+		}
+	}
+	return encodeResponse{Address: a.String(), Bytes: hex.EncodeToString(a.Bytes())}, nil
+}
+
+func protocolByName(name string) (address.Protocol, bool) {
+	for p, n := range protocolNames {
+		if n == name {
+			return p, true
+		}
+	}
+	return address.Unknown, false
+}
+
+type deriveRequest struct {
+	Type      string `json:"type"`
+	PublicKey string `json:"publicKey"`
+	Subnet    string `json:"subnet,omitempty"`
+	Network   string `json:"network,omitempty"`
+}
+
+func (s *server) derive(decode func(v interface{}) error) (interface{}, error) {
+	var req deriveRequest
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	network, err := requestNetwork(req.Network)
+	if err != nil {
+		return nil, err
+	}
+	if network != nil {
+		address.CurrentNetwork = *network
+	}
+
+	pub, ki, err := decodeKeyInput([]byte(req.PublicKey), InputAuto)
+	if err != nil {
+		return nil, &httpError{http.StatusBadRequest, err}
+	}
+	if ki != nil {
+		return nil, &httpError{http.StatusBadRequest, xerrors.Errorf("private keys are not accepted")}
+	}
+	a, err := addrFromPubicKeyByType(pub, req.Type)
+	if err != nil {
+		return nil, err
+	}
+	if req.Subnet != "" {
+		sn, err := address.ParseSubnetID(req.Subnet)
+		if err != nil {
+			return nil, err
+		}
+		if a, err = address.NewHCAddress(sn, a); err != nil {
+			return nil, err
+		}
+	}
+	return addressResponse{a.String()}, nil
+}
+
+type checksumRequest struct {
+	Address string `json:"address,omitempty"`
+	Data    string `json:"data,omitempty"`
+}
+
+type checksumResponse struct {
+	*validation
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// checksum validates an address and returns its checksum, or returns the
+// checksum of arbitrary data.
+func (s *server) checksum(decode func(v interface{}) error) (interface{}, error) {
+	var req checksumRequest
+	if err := decode(&req); err != nil {
+		return nil, err
+	}
+	if (req.Address == "") == (req.Data == "") {
+		return nil, &httpError{http.StatusBadRequest, xerrors.Errorf("exactly one of address and data must be set")}
+	}
+
+	if req.Data != "" {
+		data, err := hex.DecodeString(req.Data)
+		if err != nil {
+			return nil, &httpError{http.StatusBadRequest, xerrors.Errorf("decoding data: %w", err)}
+		}
+		return checksumResponse{Checksum: hex.EncodeToString(address.Checksum(data))}, nil
+	}
+
+	v := validateAddress(req.Address)
+	res := checksumResponse{validation: &v}
+	if a, err := address.NewFromString(req.Address); err == nil && a.Protocol() != address.ID {
+		res.Checksum = hex.EncodeToString(address.Checksum(a.Bytes()))
+	}
+	return res, nil
+}
+
+type subnetRequest struct {
+	Subnets []string `json:"subnets"`
+	Network string   `json:"network,omitempty"`
+}
+
+func (s *server) subnet(cmd subnetCommand) endpoint {
+	return func(decode func(v interface{}) error) (interface{}, error) {
+		var req subnetRequest
+		if err := decode(&req); err != nil {
+			return nil, err
+		}
+		network, err := requestNetwork(req.Network)
+		if err != nil {
+			return nil, err
+		}
+		if len(req.Subnets) != cmd.nargs {
+			return nil, &httpError{http.StatusBadRequest, xerrors.Errorf("%s expects %d subnets, got %d", cmd.name, cmd.nargs, len(req.Subnets))}
+		}
+		return runSubnetCommand(cmd, req.Subnets, network)
+	}
+}
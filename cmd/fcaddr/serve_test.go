@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func newTestServer(maxBody int64, maxInflight int) *server {
+	return &server{
+		network:  address.Mainnet,
+		maxBody:  maxBody,
+		inflight: make(chan struct{}, maxInflight),
+	}
+}
+
+// serve sends a request to h and returns the status and decoded JSON body of
+// the response.
+func serve(t *testing.T, h http.Handler, method, path, body string) (int, map[string]interface{}) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var res map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res), rec.Body.String())
+	return rec.Code, res
+}
+
+func TestServeEndpoints(t *testing.T) {
+	h := newTestServer(DefaultMaxBody, DefaultMaxInflight).handler()
+	pub := bytes.Repeat([]byte{4}, 65)
+	secp, err := address.NewSecp256k1Address(pub)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		path, body string
+		expected   map[string]interface{}
+	}{
+		{"/v1/decode", `{"address":"t01000"}`, map[string]interface{}{
+			"address": "t01000", "network": NetTestnet, "protocol": "id", "payload": "e807", "id": 1000.0,
+		}},
+		{"/v1/convert", `{"address":"t01000"}`, map[string]interface{}{"address": "f01000"}},
+		{"/v1/convert", `{"address":"f01000","network":"mainnet"}`, map[string]interface{}{"address": "f01000"}},
+		// Addresses are encoded for the network of the server by default.
+		{"/v1/encode", `{"protocol":"id","id":1000}`, map[string]interface{}{"address": "f01000", "bytes": "00e807"}},
+		{"/v1/encode", `{"protocol":"id","payload":"e807","network":"testnet"}`, map[string]interface{}{"address": "t01000", "bytes": "00e807"}},
+		{"/v1/derive", `{"type":"secp256k1","publicKey":"` + hex.EncodeToString(pub) + `"}`, map[string]interface{}{
+			"address": "f" + secp.String()[1:],
+		}},
+		{"/v1/checksum", `{"data":"00"}`, map[string]interface{}{"checksum": hex.EncodeToString(address.Checksum([]byte{0}))}},
+		{"/v1/checksum", `{"address":"f01000"}`, map[string]interface{}{"input": "f01000", "valid": true}},
+		{"/v1/checksum", `{"address":"x01000"}`, map[string]interface{}{
+			"input": "x01000", "valid": false, "class": ClassUnknownNetwork, "error": "unknown address network",
+			"candidates": []interface{}{"f01000", "t01000"},
+		}},
+		{"/v1/subnet/parent", `{"subnets":["/root/t01"]}`, map[string]interface{}{"subnet": "/root"}},
+		{"/v1/subnet/common-parent", `{"subnets":["/root/f01/f02","/root/f01/f03"]}`, map[string]interface{}{"subnet": "/root/f01", "level": 2.0}},
+	}
+
+	for _, tc := range testCases {
+		status, res := serve(t, h, http.MethodPost, tc.path, tc.body)
+		require.Equal(t, http.StatusOK, status, tc.body)
+		require.Equal(t, tc.expected, res, tc.body)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.True(t, json.Valid(rec.Body.Bytes()))
+}
+
+func TestServeErrors(t *testing.T) {
+	h := newTestServer(DefaultMaxBody, DefaultMaxInflight).handler()
+
+	testCases := []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodGet, "/v1/decode", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/openapi.json", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/v1/decode", `{"address":`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/decode", `{"address":"f01000","unknown":1}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/decode", `{"address":"f01000","network":"devnet"}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/decode", `{"address":"x01000"}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/v1/encode", `{"protocol":"bls","id":1}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/encode", `{"protocol":"unknown","payload":"00"}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/encode", `{"protocol":"secp256k1","payload":"00"}`, http.StatusUnprocessableEntity},
+		{http.MethodPost, "/v1/derive", `{"type":"secp256k1","publicKey":"not a key"}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/checksum", `{}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/checksum", `{"address":"f01000","data":"00"}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/subnet/parent", `{"subnets":["/root","/root"]}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/subnet/parent", `{"subnets":["/other"]}`, http.StatusUnprocessableEntity},
+	}
+
+	for _, tc := range testCases {
+		status, res := serve(t, h, tc.method, tc.path, tc.body)
+		require.Equal(t, tc.status, status, tc.body)
+		require.Len(t, res, 1, tc.body)
+		require.NotEmpty(t, res["error"], tc.body)
+	}
+}
+
+func TestServeMaxBody(t *testing.T) {
+	h := newTestServer(32, DefaultMaxInflight).handler()
+
+	body := `{"address":"f01000"}`
+	body += strings.Repeat(" ", 32-len(body))
+	status, _ := serve(t, h, http.MethodPost, "/v1/decode", body)
+	require.Equal(t, http.StatusOK, status)
+
+	status, res := serve(t, h, http.MethodPost, "/v1/decode", body+" ")
+	require.Equal(t, http.StatusRequestEntityTooLarge, status)
+	require.Equal(t, "request body larger than 32 bytes", res["error"])
+}
+
+func TestServeMaxInflight(t *testing.T) {
+	s := newTestServer(DefaultMaxBody, 1)
+	entered, release := make(chan struct{}), make(chan struct{})
+	h := s.handle(func(decode func(v interface{}) error) (interface{}, error) {
+		close(entered)
+		<-release
+		return addressResponse{"f01000"}, nil
+	})
+
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}")))
+		done <- rec.Code
+	}()
+	<-entered
+
+	// Requests beyond the limit are rejected rather than queued.
+	status, res := serve(t, h, http.MethodPost, "/", "{}")
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, "too many requests", res["error"])
+
+	close(release)
+	require.Equal(t, http.StatusOK, <-done)
+
+	// The slot is released with the request.
+	release = make(chan struct{})
+	close(release)
+	entered = make(chan struct{})
+	status, _ = serve(t, h, http.MethodPost, "/", "{}")
+	require.Equal(t, http.StatusOK, status)
+}
+
+func TestServeNetwork(t *testing.T) {
+	network := address.CurrentNetwork
+	h := newTestServer(DefaultMaxBody, DefaultMaxInflight).handler()
+
+	// Requests for different networks do not see each other's network.
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name, prefix := NetMainnet, address.MainnetPrefix
+			if i%2 == 0 {
+				name, prefix = NetTestnet, address.TestnetPrefix
+			}
+			rec := httptest.NewRecorder()
+			body := `{"protocol":"id","id":1000,"network":"` + name + `"}`
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/encode", strings.NewReader(body)))
+
+			var res encodeResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil || res.Address != prefix+"01000" {
+				t.Errorf("got %s for %s", rec.Body.String(), name)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// The global network is restored after each request.
+	require.Equal(t, network, address.CurrentNetwork)
+}
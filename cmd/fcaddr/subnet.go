@@ -0,0 +1,158 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// subnetCommand is an fcaddr subnet subcommand taking nargs subnet IDs.
+type subnetCommand struct {
+	name  string
+	args  string
+	usage string
+	nargs int
+	run   func(sns []address.SubnetID) (subnetResult, error)
+}
+
+// subnetResult is the outcome of a subnet subcommand.
+type subnetResult struct {
+	Subnet string `json:"subnet"`
+	Level  *int   `json:"level,omitempty"`
+}
+
+func (r subnetResult) String() string {
+	if r.Level != nil {
+		return fmt.Sprintf("%s\t%d", r.Subnet, *r.Level)
+	}
+	return r.Subnet
+}
+
+var subnetCommands = []subnetCommand{
+	{"parent", "<subnet>", "print the parent of a subnet", 1, subnetParent},
+	{"common-parent", "<subnet> <subnet>", "print the common parent of two subnets and its depth", 2, subnetCommonParent},
+	{"up", "<subnet> <current>", "print the next subnet going up from current towards the common parent with subnet", 2, subnetUp},
+	{"down", "<subnet> <current>", "print the next subnet going down from current towards subnet", 2, subnetDown},
+}
+
+func runSubnet(args []string) error {
+	fs := flag.NewFlagSet("subnet", flag.ExitOnError)
+	netName := networkFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s subnet [--network <network>] <command> <subnet>...\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Subnets are printed for the network of the first argument unless --network is given.\n\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(fs.Output(), "\nCommands:\n")
+		for _, cmd := range subnetCommands {
+			fmt.Fprintf(fs.Output(), "  %-14s %-19s %s\n", cmd.name, cmd.args, cmd.usage)
+		}
+	}
+	_ = fs.Parse(args)
+
+	network, err := parseNetwork(*netName)
+	if err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	cmd, ok := findSubnetCommand(fs.Arg(0))
+	if !ok {
+		return xerrors.Errorf("unknown subnet command '%s'", fs.Arg(0))
+	}
+	if fs.NArg()-1 != cmd.nargs {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	out, err := runSubnetCommand(cmd, fs.Args()[1:], network)
+	if err != nil {
+		return err
+	}
+	fmt.Println(out)
+	return nil
+}
+
+func findSubnetCommand(name string) (subnetCommand, bool) {
+	for _, cmd := range subnetCommands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return subnetCommand{}, false
+}
+
+// runSubnetCommand runs cmd over the given subnet strings. The result is
+// printed for the given network, or the network of the first subnet if nil.
+func runSubnetCommand(cmd subnetCommand, args []string, network *address.Network) (subnetResult, error) {
+	if len(args) != cmd.nargs {
+		return subnetResult{}, xerrors.Errorf("%s expects %d subnets, got %d", cmd.name, cmd.nargs, len(args))
+	}
+
+	sns := make([]address.SubnetID, cmd.nargs)
+	for i, s := range args {
+		var err error
+		if sns[i], err = address.ParseSubnetID(s); err != nil {
+			return subnetResult{}, err
+		}
+	}
+
+	if network != nil {
+		address.CurrentNetwork = *network
+	} else if n, ok := subnetNetwork(args[0]); ok {
+		address.CurrentNetwork = n
+	}
+	return cmd.run(sns)
+}
+
+// subnetNetwork returns the network a subnet string was written for, if it
+// has any segment below the root.
+func subnetNetwork(s string) (address.Network, bool) {
+	rest := strings.TrimPrefix(s, address.RootStr+address.SubnetSeparator)
+	if rest == s {
+		return 0, false
+	}
+	n, err := networkFromString(rest)
+	return n, err == nil
+}
+
+func subnetParent(sns []address.SubnetID) (subnetResult, error) {
+	if sns[0] == address.RootSubnet {
+		return subnetResult{}, xerrors.Errorf("the root subnet has no parent")
+	}
+	p, err := sns[0].GetParent()
+	if err != nil {
+		return subnetResult{}, err
+	}
+	return subnetResult{Subnet: p.String()}, nil
+}
+
+func subnetCommonParent(sns []address.SubnetID) (subnetResult, error) {
+	p, l := sns[0].CommonParent(sns[1])
+	if p == address.UndefSubnetID {
+		return subnetResult{}, xerrors.Errorf("subnets have no common parent")
+	}
+	return subnetResult{Subnet: p.String(), Level: &l}, nil
+}
+
+func subnetUp(sns []address.SubnetID) (subnetResult, error) {
+	return subnetRoute(sns[0].Up(sns[1]))
+}
+
+func subnetDown(sns []address.SubnetID) (subnetResult, error) {
+	return subnetRoute(sns[0].Down(sns[1]))
+}
+
+func subnetRoute(sn address.SubnetID) (subnetResult, error) {
+	if sn == address.UndefSubnetID {
+		return subnetResult{}, xerrors.Errorf("no route between subnets")
+	}
+	return subnetResult{Subnet: sn.String()}, nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// runSubnetArgs runs the subnet command name over the given subnet strings.
+func runSubnetArgs(t *testing.T, name string, args ...string) (string, error) {
+	cmd, ok := findSubnetCommand(name)
+	require.True(t, ok, name)
+	res, err := runSubnetCommand(cmd, args, nil)
+	return res.String(), err
+}
+
+func TestSubnetCommands(t *testing.T) {
+	defer func(network address.Network) { address.CurrentNetwork = network }(address.CurrentNetwork)
+	address.CurrentNetwork = address.Testnet
+
+	actor, err := address.NewActorAddress([]byte("actor"))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		cmd  string
+		args []string
+		out  string
+		err  bool
+	}{
+		{"parent", []string{"/root/t01/t02"}, "/root/t01", false},
+		{"parent", []string{"/root/t01"}, "/root", false},
+		{"parent", []string{"/root"}, "", true},
+		{"parent", []string{"/other/t01"}, "", true},
+
+		{"common-parent", []string{"/root/t01/t03", "/root/t01/t02"}, "/root/t01\t2", false},
+		{"common-parent", []string{"/root/t01", "/root/t01"}, "/root/t01\t2", false},
+		// unrelated paths only share the root.
+		{"common-parent", []string{"/root/t01/t03", "/root/t02/t04"}, "/root\t1", false},
+		{"common-parent", []string{"/root", "/root/t01"}, "/root\t1", false},
+
+		{"up", []string{"/root/t01", "/root/t02/t03"}, "", true},
+		{"up", []string{"/root/t01/t02", "/root/t01/t02"}, "/root/t01", false},
+		{"up", []string{"/root/t01", "/root"}, "", true},
+
+		{"down", []string{"/root/t01/t02", "/root"}, "/root/t01", false},
+		{"down", []string{"/root/t01/t02", "/root/t01"}, "/root/t01/t02", false},
+		{"down", []string{"/root/t01/t02", "/root/t01/t02"}, "", true},
+		{"down", []string{"/root/t01", "/root/t02"}, "", true},
+		// subnet segments must be ID addresses.
+		{"down", []string{"/root/" + actor.String(), "/root"}, "", true},
+	}
+
+	for _, tc := range testCases {
+		out, err := runSubnetArgs(t, tc.cmd, tc.args...)
+		if tc.err {
+			require.Error(t, err, "%s %v", tc.cmd, tc.args)
+			continue
+		}
+		require.NoError(t, err, "%s %v", tc.cmd, tc.args)
+		require.Equal(t, tc.out, out, "%s %v", tc.cmd, tc.args)
+	}
+
+	cmd, ok := findSubnetCommand("common-parent")
+	require.True(t, ok)
+	_, err = runSubnetCommand(cmd, []string{"/root"}, nil)
+	require.Error(t, err)
+	_, ok = findSubnetCommand("sibling")
+	require.False(t, ok)
+}
+
+func TestSubnetNetwork(t *testing.T) {
+	n, ok := subnetNetwork("/root/f01")
+	require.True(t, ok)
+	require.Equal(t, address.Mainnet, n)
+	n, ok = subnetNetwork("/root/t01/t02")
+	require.True(t, ok)
+	require.Equal(t, address.Testnet, n)
+	_, ok = subnetNetwork("/root")
+	require.False(t, ok)
+}
+
+func TestAddrFromInputByType(t *testing.T) {
+	defer func(network address.Network) { address.CurrentNetwork = network }(address.CurrentNetwork)
+	address.CurrentNetwork = address.Testnet
+
+	sn, err := address.ParseSubnetID("/root/t01")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		input   string
+		keyType string
+		subnet  address.SubnetID
+		out     string
+	}{
+		{"1000", KTID, address.UndefSubnetID, "t01000"},
+		{"0", KTID, address.UndefSubnetID, "t00"},
+		{"0102", KTActor, address.UndefSubnetID, "t2nfu566zf26mim7wgkd5ytadnmkcny47foagcpwa"},
+		{"t01000", KTHierarchical, sn, "t4bebs64tpn52c6zrqgeaoqb2cxtf62"},
+	}
+	for _, tc := range testCases {
+		a, err := addrFromInputByType([]byte(tc.input), tc.keyType, InputHex, tc.subnet)
+		require.NoError(t, err, tc.input)
+		require.Equal(t, tc.out, a.String(), tc.input)
+	}
+
+	for _, tc := range []struct {
+		input   string
+		keyType string
+		subnet  address.SubnetID
+	}{
+		{"x", KTID, address.UndefSubnetID},
+		{"-1", KTID, address.UndefSubnetID},
+		{"zz", KTActor, address.UndefSubnetID},
+		{"x01000", KTHierarchical, sn},
+		// hierarchical addresses cannot be nested, and need a subnet.
+		{"/root:t01000", KTHierarchical, sn},
+		{"t01000", KTHierarchical, address.UndefSubnetID},
+		{"0102", "other", address.UndefSubnetID},
+	} {
+		_, err := addrFromInputByType([]byte(tc.input), tc.keyType, InputHex, tc.subnet)
+		require.Error(t, err, tc.input)
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// Failure classes reported by validate.
+const (
+	ClassEmpty           = "empty"
+	ClassUnknownNetwork  = "unknown-network"
+	ClassUnknownProtocol = "unknown-protocol"
+	ClassBadLength       = "bad-length"
+	ClassBadChecksum     = "bad-checksum"
+	ClassNonCanonical    = "non-canonical"
+	ClassIDOverflow      = "id-overflow"
+	ClassBadPayload      = "bad-payload"
+)
+
+// Exit codes of validate.
+const (
+	ExitValid   = 0
+	ExitInvalid = 1
+	ExitFailure = 2
+)
+
+// maxCandidates is the maximum number of typo corrections given per address.
+const maxCandidates = 5
+
+// candidateAlphabet holds every character a single typo can be corrected to.
+const candidateAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+
+// addressToken matches anything that looks like an address in --scan mode.
+var addressToken = regexp.MustCompile(`\b[ft][0-9][a-z0-9]+\b`)
+
+// validation is the outcome of validating one address.
+type validation struct {
+	Line       int      `json:"line,omitempty"`
+	Column     int      `json:"column,omitempty"`
+	Input      string   `json:"input"`
+	Valid      bool     `json:"valid"`
+	Class      string   `json:"class,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+func runValidate(args []string) error {
+	var (
+		scan   bool
+		quiet  bool
+		format = FormatTSV
+	)
+
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.BoolVar(&scan, "scan", scan, "validate everything that looks like an address in the input, e.g. a config file")
+	fs.BoolVar(&quiet, "quiet", quiet, "only print invalid addresses")
+	fs.StringVar(&format, "format", format, fmt.Sprintf("output format [%s]", strings.Join(ValidBatchFormats, ", ")))
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s validate [--scan] [--quiet] [--format <format>] [address...]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Validates the given addresses, or one address per line of stdin. Invalid\n")
+		fmt.Fprintf(fs.Output(), "addresses are reported with a failure class and, when a single typo would\n")
+		fmt.Fprintf(fs.Output(), "make them valid, the corrected addresses.\n\n")
+		fmt.Fprintf(fs.Output(), "Failure classes: %s.\n\n", strings.Join([]string{
+			ClassEmpty, ClassUnknownNetwork, ClassUnknownProtocol, ClassBadLength,
+			ClassBadChecksum, ClassNonCanonical, ClassIDOverflow, ClassBadPayload,
+		}, ", "))
+		fmt.Fprintf(fs.Output(), "Exits with %d if all addresses are valid, %d if any is invalid and %d if the\n", ExitValid, ExitInvalid, ExitFailure)
+		fmt.Fprintf(fs.Output(), "input could not be read.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if format != FormatTSV && format != FormatJSONL {
+		fmt.Printf("error: invalid format '%s' [%s]\n", format, strings.Join(ValidBatchFormats, ", "))
+		return exitError{code: ExitFailure}
+	}
+
+	var results []validation
+	if fs.NArg() > 0 {
+		for i, s := range fs.Args() {
+			if scan {
+				results = append(results, scanLine(s, i+1)...)
+				continue
+			}
+			v := validateAddress(s)
+			v.Line = i + 1
+			results = append(results, v)
+		}
+	} else {
+		var err error
+		if results, err = validateReader(os.Stdin, scan); err != nil {
+			fmt.Printf("error: reading input: %s\n", err)
+			return exitError{code: ExitFailure}
+		}
+	}
+
+	bw := bufio.NewWriter(os.Stdout)
+	enc := json.NewEncoder(bw)
+	var invalid int
+	for _, v := range results {
+		if !v.Valid {
+			invalid++
+		} else if quiet {
+			continue
+		}
+
+		switch format {
+		case FormatJSONL:
+			if err := enc.Encode(v); err != nil {
+				return err
+			}
+		case FormatTSV:
+			fmt.Fprintln(bw, v.tsv())
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	if invalid > 0 {
+		fmt.Fprintf(os.Stderr, "error: %d of %d addresses are invalid\n", invalid, len(results))
+		return exitError{code: ExitInvalid}
+	}
+	return nil
+}
+
+// validateReader validates every non-empty line of r, or every address found
+// in it in scan mode.
+func validateReader(r io.Reader, scan bool) ([]validation, error) {
+	var results []validation
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		if scan {
+			results = append(results, scanLine(sc.Text(), n)...)
+			continue
+		}
+		input := strings.TrimSpace(sc.Text())
+		if input == "" {
+			continue
+		}
+		v := validateAddress(input)
+		v.Line = n
+		results = append(results, v)
+	}
+	return results, sc.Err()
+}
+
+// scanLine validates every address-like token of a line.
+func scanLine(line string, n int) []validation {
+	var results []validation
+	for _, loc := range addressToken.FindAllStringIndex(line, -1) {
+		v := validateAddress(line[loc[0]:loc[1]])
+		v.Line, v.Column = n, loc[0]+1
+		results = append(results, v)
+	}
+	return results
+}
+
+// validateAddress checks that s is the canonical string of an address,
+// classifying the failure and looking for typo corrections otherwise.
+func validateAddress(s string) validation {
+	v := validation{Input: s}
+	class, err := classify(s)
+	if err == nil {
+		v.Valid = true
+		return v
+	}
+	v.Class, v.Error = class, err.Error()
+	v.Candidates = typoCandidates(s, class)
+	return v
+}
+
+// classify returns the failure class of s and the decoding error, or an
+// empty class and a nil error if s is a valid address.
+func classify(s string) (string, error) {
+	if s == "" || s == address.UndefAddressString {
+		return ClassEmpty, xerrors.Errorf("empty address")
+	}
+
+	// decode does not tell overflowing IDs apart from other payloads.
+	if len(s) > 2 && s[1] == '0' && (s[0] == address.MainnetPrefix[0] || s[0] == address.TestnetPrefix[0]) {
+		if _, err := strconv.ParseUint(s[2:], 10, 63); errors.Is(err, strconv.ErrRange) {
+			return ClassIDOverflow, xerrors.Errorf("ID %s does not fit in 63 bits", s[2:])
+		}
+	}
+
+	a, err := address.NewFromString(s)
+	if err == nil {
+		// Decoding accepts any input it can make sense of, the canonical
+		// form is checked by encoding it back.
+		if c := encodedString(a, s[0]); c != s {
+			return ClassNonCanonical, xerrors.Errorf("not the canonical form %s", c)
+		}
+		return "", nil
+	}
+
+	var corrupt base32.CorruptInputError
+	switch {
+	case errors.Is(err, address.ErrUnknownNetwork):
+		return ClassUnknownNetwork, err
+	case errors.Is(err, address.ErrUnknownProtocol):
+		return ClassUnknownProtocol, err
+	case errors.Is(err, address.ErrInvalidLength):
+		return ClassBadLength, err
+	case errors.Is(err, address.ErrInvalidChecksum):
+		return ClassBadChecksum, err
+	case errors.Is(err, address.ErrInvalidEncoding):
+		// Some numbers of base32 characters cannot encode whole bytes.
+		switch len(s[2:]) % 8 {
+		case 1, 3, 6:
+			return ClassBadLength, xerrors.Errorf("%d base32 characters do not encode whole bytes: %w", len(s[2:]), address.ErrInvalidLength)
+		}
+		return ClassNonCanonical, err
+	case errors.As(err, &corrupt):
+		// Offsets are relative to the payload.
+		return ClassNonCanonical, xerrors.Errorf("illegal base32 data at input byte %d", int64(corrupt)+2)
+	default:
+		return ClassBadPayload, err
+	}
+}
+
+// encodedString returns the string of a for the network of the given prefix.
+func encodedString(a address.Address, prefix byte) string {
+	network := address.CurrentNetwork
+	defer func() { address.CurrentNetwork = network }()
+
+	if string(prefix) == address.MainnetPrefix {
+		address.CurrentNetwork = address.Mainnet
+	} else {
+		address.CurrentNetwork = address.Testnet
+	}
+	return a.String()
+}
+
+// typoCandidates returns the valid addresses that differ from s by a single
+// typo: a wrong case, a substituted, deleted, inserted or transposed
+// character. Network and protocol are only corrected for the corresponding
+// failure classes, and ID payloads never, as most edits of an ID are valid.
+func typoCandidates(s string, class string) []string {
+	var candidates []string
+	seen := map[string]bool{s: true}
+	try := func(c string) bool {
+		if seen[c] {
+			return len(candidates) < maxCandidates
+		}
+		seen[c] = true
+		if class, _ := classify(c); class == "" {
+			candidates = append(candidates, c)
+		}
+		return len(candidates) < maxCandidates
+	}
+
+	if lower := strings.ToLower(s); lower != s {
+		try(lower)
+		if len(candidates) > 0 {
+			return candidates
+		}
+	}
+
+	if len(s) < 2 {
+		return candidates
+	}
+	switch class {
+	case ClassEmpty:
+		return candidates
+	case ClassNonCanonical:
+		if a, err := address.NewFromString(s); err == nil {
+			try(encodedString(a, s[0]))
+			return candidates
+		}
+	case ClassUnknownNetwork:
+		for _, n := range []string{address.MainnetPrefix, address.TestnetPrefix} {
+			try(n + s[1:])
+		}
+		return candidates
+	case ClassUnknownProtocol:
+		for p := '0'; p <= '4'; p++ {
+			try(s[:1] + string(p) + s[2:])
+		}
+		return candidates
+	}
+	if s[1] == '0' {
+		return candidates
+	}
+
+	prefix, payload := s[:2], s[2:]
+	for i := 0; i < len(payload); i++ {
+		for _, c := range candidateAlphabet {
+			if !try(prefix + payload[:i] + string(c) + payload[i+1:]) {
+				return candidates
+			}
+		}
+	}
+	for i := 0; i+1 < len(payload); i++ {
+		if !try(prefix + payload[:i] + payload[i+1:i+2] + payload[i:i+1] + payload[i+2:]) {
+			return candidates
+		}
+	}
+	for i := 0; i < len(payload); i++ {
+		if !try(prefix + payload[:i] + payload[i+1:]) {
+			return candidates
+		}
+	}
+	for i := 0; i <= len(payload); i++ {
+		for _, c := range candidateAlphabet {
+			if !try(prefix + payload[:i] + string(c) + payload[i:]) {
+				return candidates
+			}
+		}
+	}
+	return candidates
+}
+
+func (v validation) tsv() string {
+	if v.Valid {
+		return fmt.Sprintf("%d\t%s\tok", v.Line, v.Input)
+	}
+	return strings.Join([]string{
+		strconv.Itoa(v.Line), v.Input, v.Class, v.Error, strings.Join(v.Candidates, ","),
+	}, "\t")
+}
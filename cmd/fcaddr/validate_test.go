@@ -0,0 +1,106 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// validSecp is a valid testnet SECP256K1 address.
+const validSecp = "t15ihq5ibzwki2b4ep2f46avlkrqzhpqgtga7pdrq"
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		input string
+		class string
+		err   error
+	}{
+		{validSecp, "", nil},
+		{"f01000", "", nil},
+		{"", ClassEmpty, nil},
+		{address.UndefAddressString, ClassEmpty, nil},
+		{"x01000", ClassUnknownNetwork, address.ErrUnknownNetwork},
+		{"T15IHQ5IBZWKI2B4EP2F46AVLKRQZHPQGTGA7PDRQ", ClassUnknownNetwork, address.ErrUnknownNetwork},
+		{"f91000", ClassUnknownProtocol, address.ErrUnknownProtocol},
+		{"t1", ClassBadLength, address.ErrInvalidLength},
+		{"f1abc", ClassBadLength, address.ErrInvalidLength},
+		{validSecp[:len(validSecp)-1], ClassBadLength, address.ErrInvalidLength},
+		{validSecp[:len(validSecp)-1] + "a", ClassBadChecksum, address.ErrInvalidChecksum},
+		{"t25ihq5ibzwki2b4ep2f46avlkrqzhpqgtga7pdrq", ClassBadChecksum, address.ErrInvalidChecksum},
+		{"t15IHQ5IBZWKI2B4EP2F46AVLKRQZHPQGTGA7PDRQ", ClassNonCanonical, nil},
+		{"f001000", ClassNonCanonical, nil},
+		{"f099999999999999999999", ClassIDOverflow, nil},
+		{"f0x", ClassBadPayload, address.ErrInvalidPayload},
+	}
+
+	for _, tc := range testCases {
+		class, err := classify(tc.input)
+		require.Equal(t, tc.class, class, tc.input)
+		if tc.class == "" {
+			require.NoError(t, err, tc.input)
+			continue
+		}
+		require.Error(t, err, tc.input)
+		if tc.err != nil {
+			require.ErrorIs(t, err, tc.err, tc.input)
+		}
+	}
+}
+
+func TestTypoCandidates(t *testing.T) {
+	testCases := []struct {
+		input      string
+		candidates []string
+	}{
+		// Wrong case.
+		{"t15IHQ5IBZWKI2B4EP2F46AVLKRQZHPQGTGA7PDRQ", []string{validSecp}},
+		{strings.ToUpper(validSecp), []string{validSecp}},
+		// Substitution, transposition and deletion.
+		{validSecp[:len(validSecp)-1] + "a", []string{validSecp}},
+		{"t15hiq5ibzwki2b4ep2f46avlkrqzhpqgtga7pdrq", []string{validSecp}},
+		{validSecp[:len(validSecp)-1], []string{validSecp}},
+		// Network and protocol.
+		{"x01000", []string{"f01000", "t01000"}},
+		{"f91000", []string{"f01000"}},
+		// Non canonical IDs.
+		{"f001000", []string{"f01000"}},
+		// IDs and empty addresses are never corrected.
+		{"f0x", nil},
+		{"f099999999999999999999", nil},
+		{"", nil},
+	}
+
+	for _, tc := range testCases {
+		class, err := classify(tc.input)
+		require.Error(t, err, tc.input)
+		require.Equal(t, tc.candidates, typoCandidates(tc.input, class), tc.input)
+	}
+}
+
+func TestValidateReader(t *testing.T) {
+	input := "f01000\n\n  x01000  \n" + validSecp + "\n"
+	results, err := validateReader(strings.NewReader(input), false)
+	require.NoError(t, err)
+	require.Equal(t, []validation{
+		{Line: 1, Input: "f01000", Valid: true},
+		{Line: 3, Input: "x01000", Class: ClassUnknownNetwork, Error: address.ErrUnknownNetwork.Error(), Candidates: []string{"f01000", "t01000"}},
+		{Line: 4, Input: validSecp, Valid: true},
+	}, results)
+
+	require.Equal(t, "1\tf01000\tok", results[0].tsv())
+	require.Equal(t, "3\tx01000\tunknown-network\tunknown address network\tf01000,t01000", results[1].tsv())
+
+	// Scan mode reports every address-like token with its column.
+	input = "owner = \"f01000\"\nworkers = [\"f0x\", \"" + validSecp + "\"]\n"
+	results, err = validateReader(strings.NewReader(input), true)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.Equal(t, validation{Line: 1, Column: 10, Input: "f01000", Valid: true}, results[0])
+	require.Equal(t, 2, results[1].Line)
+	require.Equal(t, 13, results[1].Column)
+	require.Equal(t, ClassBadPayload, results[1].Class)
+	require.True(t, results[2].Valid)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-address/keys"
+	"github.com/filecoin-project/go-address/vanity"
+)
+
+var ValidVanityTypes = []string{vanity.Secp256k1, vanity.BLS, vanity.Actor}
+
+func runVanity(args []string) error {
+	var (
+		typ     string
+		prefix  string
+		suffix  string
+		workers int
+		timeout time.Duration
+		quiet   bool
+		format  = FormatJSON
+	)
+
+	fs := flag.NewFlagSet("vanity", flag.ExitOnError)
+	fs.StringVar(&typ, "type", typ, fmt.Sprintf("type of address to search [%s]", strings.Join(ValidVanityTypes, ", ")))
+	fs.StringVar(&prefix, "prefix", prefix, "characters the address must start with, after its network and protocol")
+	fs.StringVar(&suffix, "suffix", suffix, "characters the address must end with")
+	fs.IntVar(&workers, "workers", workers, "number of parallel workers, defaults to the number of CPUs")
+	fs.DurationVar(&timeout, "timeout", timeout, "give up after this long")
+	fs.BoolVar(&quiet, "quiet", quiet, "do not report progress on stderr")
+	fs.StringVar(&format, "format", format, fmt.Sprintf("output format of keys [%s]", strings.Join(ValidKeygenFormats, ", ")))
+	netName := networkFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s vanity --type <type> [--prefix <prefix>] [--suffix <suffix>] [--workers <n>] [--timeout <duration>]\n\n", os.Args[0])
+		fmt.Fprintf(fs.Output(), "Searches random keys or actor seeds for an address matching the given base32\n")
+		fmt.Fprintf(fs.Output(), "prefix and suffix, and prints it with its secret. Every character makes the\n")
+		fmt.Fprintf(fs.Output(), "search up to 32 times longer; the expected number of attempts is printed first.\n\n")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if typ == "" || fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if format != FormatJSON && format != FormatLotusKey {
+		return xerrors.Errorf("invalid format '%s' [%s]", format, strings.Join(ValidKeygenFormats, ", "))
+	}
+	if format == FormatLotusKey && typ == vanity.Actor {
+		return xerrors.Errorf("actor addresses have no key to export")
+	}
+
+	network, err := parseNetwork(*netName)
+	if err != nil {
+		return err
+	}
+	if network != nil {
+		address.CurrentNetwork = *network
+	}
+
+	prefix, suffix = strings.ToLower(prefix), strings.ToLower(suffix)
+	expected, err := vanity.ExpectedAttempts(typ, prefix, suffix)
+	if err != nil {
+		return err
+	}
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "expected attempts: %.0f\n", expected)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	opts := vanity.Options{
+		Type:    typ,
+		Prefix:  prefix,
+		Suffix:  suffix,
+		Workers: workers,
+	}
+	if !quiet {
+		opts.Progress = func(p vanity.Progress) {
+			fmt.Fprintf(os.Stderr, "%d attempts in %s (%.0f/s, %.1f%% of expected)\n",
+				p.Attempts, p.Elapsed.Truncate(time.Second), p.Rate(), 100*float64(p.Attempts)/p.Expected)
+		}
+	}
+
+	res, err := vanity.Search(ctx, opts)
+	if err != nil {
+		return xerrors.Errorf("searching: %w", err)
+	}
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "found after %d attempts\n", res.Attempts)
+	}
+
+	if format == FormatLotusKey {
+		ki := keys.KeyInfo{Type: res.Type, PrivateKey: res.PrivateKey}
+		return writeLotusKey(os.Stdout, os.Stderr, ki, res.PublicKey, res.Address)
+	}
+
+	return json.NewEncoder(os.Stdout).Encode(generatedKey{
+		Type:       res.Type,
+		PrivateKey: hex.EncodeToString(res.PrivateKey),
+		PublicKey:  hex.EncodeToString(res.PublicKey),
+		Seed:       hex.EncodeToString(res.Seed),
+		Address:    res.Address.String(),
+	})
+}
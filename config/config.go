@@ -0,0 +1,309 @@
+// Package config decodes YAML and TOML configuration files holding addresses
+// and subnet IDs.
+//
+// Address and SubnetID implement encoding.TextUnmarshaler, so they can be
+// decoded with yaml.v3 and BurntSushi/toml directly, but neither library
+// reports which key failed. UnmarshalYAML and UnmarshalTOML first decode the
+// values of every address and subnet ID field against their own key, and
+// report the key of invalid ones in a *KeyError, before decoding the whole
+// document.
+//
+// Unlike Address.UnmarshalText, they reject empty addresses: a key set to ""
+// or to address.UndefAddressString is reported as an error wrapping
+// address.ErrUndefNotAllowed, as it most likely stands for a missing value.
+// Keys left out of the document keep their value.
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// KeyError is returned for values that failed to decode as an address or a
+// subnet ID.
+type KeyError struct {
+	// Key is the path of the value, such as routes.main.subnet or
+	// senders[1].
+	Key string
+	// Line is the line of the value, or 0 if unknown.
+	Line int
+	// Err is the error of the address parser, an *address.AddressParseError
+	// or an *address.SubnetParseError.
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: key %s: %s", e.Line, e.Key, e.Err)
+	}
+	return fmt.Sprintf("key %s: %s", e.Key, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	addressType     = reflect.TypeOf(address.Address{})
+	subnetIDType    = reflect.TypeOf(address.SubnetID{})
+	yamlUnmarshaler = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	tomlUnmarshaler = reflect.TypeOf((*toml.Unmarshaler)(nil)).Elem()
+	textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	emptyInterface  = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// UnmarshalYAML decodes data into v as yaml.Unmarshal does, returning a
+// *KeyError for values that are not valid addresses or subnet IDs.
+func UnmarshalYAML(data []byte, v interface{}) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if err := checkYAML(&doc, reflect.TypeOf(v), ""); err != nil {
+		return err
+	}
+	return doc.Decode(v)
+}
+
+// UnmarshalTOML decodes data into v as toml.Unmarshal does, returning a
+// *KeyError for values that are not valid addresses or subnet IDs.
+func UnmarshalTOML(data []byte, v interface{}) error {
+	var tree map[string]interface{}
+	md, err := toml.Decode(string(data), &tree)
+	if err != nil {
+		return err
+	}
+
+	// Tables are walked in the order of their keys in the document, so the
+	// first invalid value is reported.
+	order := make(map[string]int)
+	for i, k := range md.Keys() {
+		if _, ok := order[k.String()]; !ok {
+			order[k.String()] = i
+		}
+	}
+	if err := checkTOML(tree, reflect.TypeOf(v), "", nil, order); err != nil {
+		return err
+	}
+	return toml.Unmarshal(data, v)
+}
+
+// checkValue decodes s as a value of type t, if t is an address or a subnet
+// ID.
+func checkValue(t reflect.Type, s string) error {
+	switch t {
+	case addressType:
+		if s == "" || s == address.UndefAddressString {
+			return &address.AddressParseError{Input: s, Err: address.ErrUndefNotAllowed}
+		}
+		var a address.Address
+		return a.UnmarshalText([]byte(s))
+	case subnetIDType:
+		var id address.SubnetID
+		return id.UnmarshalText([]byte(s))
+	}
+	return nil
+}
+
+// checkYAML checks the addresses and subnet IDs of n, decoded as t.
+func checkYAML(n *yaml.Node, t reflect.Type, path string) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		for _, c := range n.Content {
+			if err := checkYAML(c, t, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	case yaml.AliasNode:
+		return checkYAML(n.Alias, t, path)
+	}
+
+	if n.Kind == yaml.ScalarNode && n.Tag != "!!null" {
+		if err := checkValue(t, n.Value); err != nil {
+			return &KeyError{Key: path, Line: n.Line, Err: err}
+		}
+		return nil
+	}
+	if reflect.PtrTo(t).Implements(yamlUnmarshaler) || reflect.PtrTo(t).Implements(textUnmarshaler) {
+		// Decoded by their own methods, or rejected by the decoder.
+		return nil
+	}
+
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, val := n.Content[i], n.Content[i+1]
+			if k.Value == "<<" && k.Tag == "!!merge" {
+				if err := checkYAML(val, t, path); err != nil {
+					return err
+				}
+				continue
+			}
+			ft, ok := fieldType(t, k.Value, "yaml")
+			if !ok {
+				continue
+			}
+			if err := checkYAML(val, ft, joinKey(path, k.Value)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+		for i, c := range n.Content {
+			if err := checkYAML(c, t.Elem(), path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkTOML checks the addresses and subnet IDs of v, a value of the tree
+// decoded by toml.Decode, decoded as t. key is the TOML key of v, without the
+// indexes of arrays.
+func checkTOML(v interface{}, t reflect.Type, path string, key toml.Key, order map[string]int) error {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if s, ok := v.(string); ok {
+		if err := checkValue(t, s); err != nil {
+			return &KeyError{Key: path, Err: err}
+		}
+		return nil
+	}
+	if reflect.PtrTo(t).Implements(tomlUnmarshaler) || reflect.PtrTo(t).Implements(textUnmarshaler) {
+		return nil
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return order[subKey(key, keys[i]).String()] < order[subKey(key, keys[j]).String()]
+		})
+		for _, k := range keys {
+			ft, ok := fieldType(t, k, "toml")
+			if !ok {
+				continue
+			}
+			if err := checkTOML(v[k], ft, joinKey(path, k), subKey(key, k), order); err != nil {
+				return err
+			}
+		}
+	case []map[string]interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+		for i, e := range v {
+			if err := checkTOML(e, t.Elem(), path+"["+strconv.Itoa(i)+"]", key, order); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return nil
+		}
+		for i, e := range v {
+			if err := checkTOML(e, t.Elem(), path+"["+strconv.Itoa(i)+"]", key, order); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldType returns the type the value of a key of a mapping is decoded as,
+// for structs and maps. Struct fields are matched as yaml.v3 does for the
+// yaml tag, and as BurntSushi/toml does for the toml tag.
+func fieldType(t reflect.Type, key string, tag string) (reflect.Type, bool) {
+	switch t.Kind() {
+	case reflect.Map:
+		return t.Elem(), true
+	case reflect.Interface:
+		return emptyInterface, true
+	case reflect.Struct:
+	default:
+		return nil, false
+	}
+
+	var fold reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		name, opts := f.Tag.Get(tag), ""
+		if i := strings.Index(name, ","); i >= 0 {
+			name, opts = name[:i], name[i:]
+		}
+		if name == "-" {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		inline := strings.Contains(opts, ",inline")
+		if tag == "toml" {
+			inline = f.Anonymous && name == "" && ft.Kind() == reflect.Struct
+		}
+		if inline {
+			if ft, ok := fieldType(ft, key, tag); ok {
+				return ft, true
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+			if tag == "yaml" {
+				name = strings.ToLower(name)
+			}
+		}
+		if name == key {
+			return f.Type, true
+		}
+		if tag == "toml" && fold == nil && strings.EqualFold(name, key) {
+			fold = f.Type
+		}
+	}
+	return fold, fold != nil
+}
+
+// subKey returns the TOML key of k in the table of key.
+func subKey(key toml.Key, k string) toml.Key {
+	return append(append(toml.Key(nil), key...), k)
+}
+
+func joinKey(path, key string) string {
+	if strings.ContainsAny(key, ".[] ") {
+		key = strconv.Quote(key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
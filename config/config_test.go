@@ -0,0 +1,171 @@
+package config_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-address/config"
+)
+
+type route struct {
+	Subnet  address.SubnetID `yaml:"subnet" toml:"subnet"`
+	Gateway address.Address  `yaml:"gateway" toml:"gateway"`
+}
+
+type nodeConfig struct {
+	Name    string                      `yaml:"name" toml:"name"`
+	Owner   address.Address             `yaml:"owner" toml:"owner"`
+	Senders []address.Address           `yaml:"senders" toml:"senders"`
+	Routes  []route                     `yaml:"routes" toml:"routes"`
+	Peers   map[string]*address.Address `yaml:"peers" toml:"peers"`
+}
+
+func testConfig(t *testing.T) nodeConfig {
+	id, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	actor, err := address.NewActorAddress([]byte("actor"))
+	require.NoError(t, err)
+	sn := address.NewSubnetID(address.RootSubnet, id)
+	hc, err := address.NewHCAddress(sn, actor)
+	require.NoError(t, err)
+
+	return nodeConfig{
+		Name:    "node",
+		Owner:   id,
+		Senders: []address.Address{id, actor, hc},
+		Routes: []route{
+			{Subnet: address.RootSubnet, Gateway: id},
+			{Subnet: sn, Gateway: hc},
+		},
+		Peers: map[string]*address.Address{"main": &actor},
+	}
+}
+
+func TestYAML(t *testing.T) {
+	cfg := testConfig(t)
+
+	data, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+
+	var decoded nodeConfig
+	require.NoError(t, config.UnmarshalYAML(data, &decoded))
+	require.Equal(t, cfg, decoded)
+
+	// The types decode with yaml.v3 alone too, and accept the pretty form
+	// of hierarchical addresses.
+	decoded = nodeConfig{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: node
+owner: `+cfg.Owner.String()+`
+senders:
+  - `+cfg.Senders[0].String()+`
+  - `+cfg.Senders[1].String()+`
+  - "`+cfg.Senders[2].PrettyPrint()+`"
+routes:
+  - subnet: /root
+    gateway: `+cfg.Routes[0].Gateway.String()+`
+  - subnet: `+cfg.Routes[1].Subnet.String()+`
+    gateway: "`+cfg.Routes[1].Gateway.PrettyPrint()+`"
+peers:
+  main: `+cfg.Peers["main"].String()+`
+`), &decoded))
+	require.Equal(t, cfg, decoded)
+}
+
+func TestYAMLErrors(t *testing.T) {
+	testCases := []struct {
+		input, key string
+		line       int
+		err        error
+	}{
+		{"owner: x01000\n", "owner", 1, address.ErrUnknownNetwork},
+		{"owner: f01000\nsenders:\n  - f01000\n  - f9abc\n", "senders[1]", 4, address.ErrUnknownProtocol},
+		{"routes:\n  - gateway: f01000\n  - subnet: /other/f01000\n", "routes[1].subnet", 3, address.ErrSubnetNotRooted},
+		{"peers:\n  main: f0abc\n", "peers.main", 2, address.ErrInvalidPayload},
+		// The key of the value that failed is reported, not the first one
+		// holding the same string.
+		{"name: f0abc\nowner: f0abc\n", "owner", 2, address.ErrInvalidPayload},
+		{"name: f0abc\nsenders: [f01000, f0abc]\n", "senders[1]", 2, address.ErrInvalidPayload},
+		// Empty addresses are rejected.
+		{"owner: \"\"\n", "owner", 1, address.ErrUndefNotAllowed},
+		{"senders:\n  - f01000\n  - <empty>\n", "senders[1]", 3, address.ErrUndefNotAllowed},
+	}
+
+	for _, tc := range testCases {
+		var decoded nodeConfig
+		err := config.UnmarshalYAML([]byte(tc.input), &decoded)
+		var kerr *config.KeyError
+		require.ErrorAs(t, err, &kerr, tc.input)
+		require.Equal(t, tc.key, kerr.Key)
+		require.Equal(t, tc.line, kerr.Line)
+		require.ErrorIs(t, err, tc.err)
+		require.Contains(t, err.Error(), tc.key)
+		require.Contains(t, err.Error(), tc.err.Error())
+	}
+
+	// Other errors are returned as is.
+	var decoded nodeConfig
+	err := config.UnmarshalYAML([]byte("senders: 1\n"), &decoded)
+	require.Error(t, err)
+	var kerr *config.KeyError
+	require.False(t, errors.As(err, &kerr))
+
+	// Missing and null values leave addresses untouched.
+	decoded = testConfig(t)
+	require.NoError(t, config.UnmarshalYAML([]byte("owner:\nname: other\n"), &decoded))
+	require.Equal(t, testConfig(t).Owner, decoded.Owner)
+}
+
+func TestTOML(t *testing.T) {
+	cfg := testConfig(t)
+
+	var buf bytes.Buffer
+	require.NoError(t, toml.NewEncoder(&buf).Encode(cfg))
+
+	var decoded nodeConfig
+	require.NoError(t, config.UnmarshalTOML(buf.Bytes(), &decoded))
+	require.Equal(t, cfg, decoded)
+
+	decoded = nodeConfig{}
+	_, err := toml.Decode(buf.String(), &decoded)
+	require.NoError(t, err)
+	require.Equal(t, cfg, decoded)
+}
+
+func TestTOMLErrors(t *testing.T) {
+	testCases := []struct {
+		input, key string
+		err        error
+	}{
+		{"owner = \"x01000\"\n", "owner", address.ErrUnknownNetwork},
+		{"senders = [\"f01000\", \"f9abc\"]\n", "senders[1]", address.ErrUnknownProtocol},
+		{"[[routes]]\ngateway = \"f01000\"\n[[routes]]\nsubnet = \"/other/f01000\"\n", "routes[1].subnet", address.ErrSubnetNotRooted},
+		{"[peers]\nmain = \"f0abc\"\n", "peers.main", address.ErrInvalidPayload},
+		// The key of the value that failed is reported, not the first one
+		// holding the same string.
+		{"name = \"f0abc\"\nowner = \"f0abc\"\n", "owner", address.ErrInvalidPayload},
+		{"name = \"f0abc\"\nsenders = [\"f01000\", \"f0abc\"]\n", "senders[1]", address.ErrInvalidPayload},
+		// The first invalid value of the document is reported.
+		{"senders = [\"f0abc\"]\nowner = \"f0abc\"\n", "senders[0]", address.ErrInvalidPayload},
+		// Empty addresses are rejected.
+		{"owner = \"\"\n", "owner", address.ErrUndefNotAllowed},
+		{"senders = [\"f01000\", \"<empty>\"]\n", "senders[1]", address.ErrUndefNotAllowed},
+	}
+
+	for _, tc := range testCases {
+		var decoded nodeConfig
+		err := config.UnmarshalTOML([]byte(tc.input), &decoded)
+		var kerr *config.KeyError
+		require.ErrorAs(t, err, &kerr, tc.input)
+		require.Equal(t, tc.key, kerr.Key)
+		require.ErrorIs(t, err, tc.err)
+		require.Contains(t, err.Error(), tc.key)
+		require.Contains(t, err.Error(), tc.err.Error())
+	}
+}
@@ -45,6 +45,38 @@ var (
 	ErrNotHierarchical = errors.New("not hierarchical address")
 	// ErrInvalidEncoding is returned when encountering a non-standard encoding of an address.
 	ErrInvalidEncoding = errors.New("invalid encoding")
+	// ErrNestedHierarchical is returned when a hierarchical address wraps another hierarchical address.
+	ErrNestedHierarchical = errors.New("nested hierarchical address")
+	// ErrUndefRawAddress is returned when a hierarchical address wraps an undefined address.
+	ErrUndefRawAddress = errors.New("undefined raw address in hierarchical address")
+	// ErrInvalidRawAddress is returned when the raw address embedded in a hierarchical address is invalid.
+	ErrInvalidRawAddress = errors.New("invalid raw address in hierarchical address")
+	// ErrUndefNotAllowed is returned when decoding an empty address with DecodeOptions that do not allow Undef.
+	ErrUndefNotAllowed = errors.New("undefined address not allowed")
+	// ErrProtocolNotAllowed is returned when decoding an address of a protocol not allowed by DecodeOptions.
+	ErrProtocolNotAllowed = errors.New("address protocol not allowed")
+	// ErrNetworkNotAllowed is returned when decoding an address of a network not allowed by DecodeOptions.
+	ErrNetworkNotAllowed = errors.New("address network not allowed")
+
+	// ErrAddressNotFound is returned when an address is not in an AddressBook.
+	ErrAddressNotFound = errors.New("address not found")
+	// ErrInvalidAddressBookEntry is returned when adding an invalid entry to an AddressBook.
+	ErrInvalidAddressBookEntry = errors.New("invalid address book entry")
+	// ErrInvalidAddressSet is returned when encountering an invalid address set encoding.
+	ErrInvalidAddressSet = errors.New("invalid address set")
+
+	// ErrSubnetNotRooted is returned when a subnet path does not start at RootStr.
+	ErrSubnetNotRooted = errors.New("subnet id not rooted at " + RootStr)
+	// ErrSubnetEmptySegment is returned when a subnet path contains duplicate separators.
+	ErrSubnetEmptySegment = errors.New("empty subnet id segment")
+	// ErrSubnetTrailingSeparator is returned when a subnet path ends with a separator.
+	ErrSubnetTrailingSeparator = errors.New("trailing subnet id separator")
+	// ErrSubnetInvalidSegment is returned when a subnet path segment is not a valid ID address.
+	ErrSubnetInvalidSegment = errors.New("subnet id segment is not a valid ID address")
+	// ErrSubnetNonCanonicalSegment is returned when a subnet path segment is not in its canonical string form.
+	ErrSubnetNonCanonicalSegment = errors.New("non-canonical subnet id segment")
+	// ErrSubnetMixedNetwork is returned when the segments of a subnet path use different network prefixes.
+	ErrSubnetMixedNetwork = errors.New("mixed networks in subnet id")
 )
 
 // UndefAddressString is the string used to represent an empty address when encoded to a string.
@@ -65,6 +97,16 @@ const ChecksumHashLength = 4
 const MaxAddressStringLength = 232
 const HierarchicalLength = 142
 
+// MaxAddressBytes is the max length of an address encoded as bytes, that of
+// hierarchical addresses. UnmarshalCBOR used to reject addresses longer than
+// 128 bytes, which cut off hierarchical addresses with long subnet paths; set
+// DecodeOptions.MaxLength to keep that limit.
+const MaxAddressBytes = 1 + HierarchicalLength
+
+// MaxAddressCBORBytes is the max length of an address encoded by MarshalCBOR,
+// and the scratch space needed by MarshalCBORBuf and UnmarshalCBORBuf.
+const MaxAddressCBORBytes = 2 + MaxAddressBytes
+
 // BlsPublicKeyBytes is the length of a BLS public key
 const BlsPublicKeyBytes = 48
 
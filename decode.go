@@ -0,0 +1,173 @@
+package address
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+)
+
+// DecodeOptions restrict the addresses accepted when decoding strings, bytes
+// or CBOR.
+//
+// The zero value applies the checks of UnmarshalCBOR, which rejects empty
+// addresses. NewFromString and NewFromBytes behave as DecodeOptions with
+// AllowUndef set.
+type DecodeOptions struct {
+	// MaxLength is the max length of the address bytes, protocol included.
+	// It defaults to, and cannot exceed, MaxAddressBytes.
+	MaxLength int
+	// AllowUndef accepts empty input as Undef: an empty string or
+	// UndefAddressString, empty bytes, an empty CBOR byte string or CBOR
+	// null, the encoding of a nil address.
+	AllowUndef bool
+	// Protocols, if not empty, lists the protocols accepted.
+	Protocols []Protocol
+	// Networks, if not empty, lists the networks accepted. Only strings carry
+	// a network, so it does not restrict the decoding of bytes and CBOR.
+	Networks []Network
+}
+
+// NewFromString returns the address represented by s, as NewFromString does,
+// if it satisfies the options.
+func (o DecodeOptions) NewFromString(s string) (Address, error) {
+	if s == "" || s == UndefAddressString {
+		return Undef, o.checkUndef()
+	}
+
+	a, err := decode(s)
+	if err != nil {
+		return Undef, err
+	}
+
+	if len(o.Networks) > 0 {
+		network := Testnet
+		if string(s[0]) == MainnetPrefix {
+			network = Mainnet
+		}
+		allowed := false
+		for _, n := range o.Networks {
+			allowed = allowed || n == network
+		}
+		if !allowed {
+			return Undef, xerrors.Errorf("network prefix %c: %w", s[0], ErrNetworkNotAllowed)
+		}
+	}
+
+	if err := o.check(a.Protocol(), len(a.str)); err != nil {
+		return Undef, err
+	}
+	return a, nil
+}
+
+// NewFromBytes returns the address represented by b, as NewFromBytes does, if
+// it satisfies the options.
+func (o DecodeOptions) NewFromBytes(b []byte) (Address, error) {
+	if len(b) == 0 {
+		return Undef, o.checkUndef()
+	}
+	if err := o.check(b[0], len(b)); err != nil {
+		return Undef, err
+	}
+	return NewFromBytes(b)
+}
+
+// UnmarshalCBOR decodes an address encoded by MarshalCBOR into a, if it
+// satisfies the options.
+func (o DecodeOptions) UnmarshalCBOR(r io.Reader, a *Address) error {
+	scratch := scratchPool.Get().(*[MaxAddressCBORBytes]byte)
+	defer scratchPool.Put(scratch)
+	return o.UnmarshalCBORBuf(r, a, scratch[:])
+}
+
+// scratchPool holds the scratch space of MarshalCBOR and UnmarshalCBOR. It
+// cannot live on the stack, as it escapes through the writer or the reader.
+var scratchPool = sync.Pool{
+	New: func() interface{} {
+		return new([MaxAddressCBORBytes]byte)
+	},
+}
+
+// UnmarshalCBORBuf is UnmarshalCBOR using the scratch space of the caller, see
+// Address.UnmarshalCBORBuf.
+func (o DecodeOptions) UnmarshalCBORBuf(r io.Reader, a *Address, scratch []byte) error {
+	if len(scratch) < MaxAddressCBORBytes {
+		return fmt.Errorf("scratch space too small to unmarshal an address")
+	}
+
+	maj, extra, err := cbg.CborReadHeaderBuf(r, scratch)
+	if err != nil {
+		return err
+	}
+
+	if maj == cbg.MajOther && extra == 22 && o.AllowUndef {
+		*a = Undef
+		return nil
+	}
+
+	if maj != cbg.MajByteString {
+		return fmt.Errorf("cbor type for address unmarshal was not byte string")
+	}
+
+	if extra > uint64(o.maxLength()) {
+		return fmt.Errorf("too many bytes to unmarshal for an address")
+	}
+	buf := scratch[:extra]
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return err
+	}
+
+	switch len(buf) {
+	case 0:
+		if !o.AllowUndef {
+			return fmt.Errorf("cbor input should not contain empty addresses")
+		}
+		*a = Undef
+		return nil
+	case 1:
+		return ErrInvalidLength
+	}
+	if err := o.check(buf[0], len(buf)); err != nil {
+		return err
+	}
+	payload, err := checkPayload(buf[0], buf[1:])
+	if err != nil {
+		return err
+	}
+
+	*a = Address{string(buf[:1+len(payload)])}
+
+	return nil
+}
+
+func (o DecodeOptions) maxLength() int {
+	if o.MaxLength <= 0 || o.MaxLength > MaxAddressBytes {
+		return MaxAddressBytes
+	}
+	return o.MaxLength
+}
+
+func (o DecodeOptions) checkUndef() error {
+	if !o.AllowUndef {
+		return ErrUndefNotAllowed
+	}
+	return nil
+}
+
+// check validates the protocol and length in bytes of an address.
+func (o DecodeOptions) check(protocol Protocol, length int) error {
+	if length > o.maxLength() {
+		return xerrors.Errorf("%d bytes exceed the max length of %d: %w", length, o.maxLength(), ErrInvalidLength)
+	}
+	if len(o.Protocols) == 0 {
+		return nil
+	}
+	for _, p := range o.Protocols {
+		if p == protocol {
+			return nil
+		}
+	}
+	return xerrors.Errorf("protocol %d: %w", protocol, ErrProtocolNotAllowed)
+}
@@ -0,0 +1,109 @@
+package address_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func TestDecodeOptionsDefault(t *testing.T) {
+	for name, a := range conformanceAddresses(t) {
+		var buf bytes.Buffer
+		require.NoError(t, a.MarshalCBOR(&buf), name)
+
+		var x address.Address
+		require.NoError(t, address.DecodeOptions{}.UnmarshalCBOR(bytes.NewReader(buf.Bytes()), &x), name)
+		require.Equal(t, a, x, name)
+
+		x, err := address.DecodeOptions{}.NewFromBytes(a.Bytes())
+		require.NoError(t, err, name)
+		require.Equal(t, a, x, name)
+
+		x, err = address.DecodeOptions{}.NewFromString(a.String())
+		require.NoError(t, err, name)
+		require.Equal(t, a, x, name)
+	}
+
+	// Empty addresses are rejected unless allowed, as by UnmarshalCBOR.
+	var x address.Address
+	require.Error(t, address.DecodeOptions{}.UnmarshalCBOR(bytes.NewReader([]byte{0x40}), &x))
+	_, err := address.DecodeOptions{}.NewFromBytes(nil)
+	require.ErrorIs(t, err, address.ErrUndefNotAllowed)
+	for _, s := range []string{"", address.UndefAddressString} {
+		_, err = address.DecodeOptions{}.NewFromString(s)
+		require.ErrorIs(t, err, address.ErrUndefNotAllowed)
+	}
+
+	// Null is the encoding of a nil address.
+	x = address.TestAddress
+	require.Error(t, address.DecodeOptions{}.UnmarshalCBOR(bytes.NewReader([]byte{0xf6}), &x))
+	require.Equal(t, address.TestAddress, x)
+}
+
+func TestDecodeOptionsAllowUndef(t *testing.T) {
+	opts := address.DecodeOptions{AllowUndef: true}
+
+	for _, in := range [][]byte{{0x40}, {0xf6}} {
+		x := address.TestAddress
+		require.NoError(t, opts.UnmarshalCBOR(bytes.NewReader(in), &x), in)
+		require.Equal(t, address.Undef, x, in)
+	}
+
+	x, err := opts.NewFromBytes(nil)
+	require.NoError(t, err)
+	require.Equal(t, address.Undef, x)
+
+	for _, s := range []string{"", address.UndefAddressString} {
+		x, err = opts.NewFromString(s)
+		require.NoError(t, err)
+		require.Equal(t, address.Undef, x)
+	}
+}
+
+func TestDecodeOptionsRestrictions(t *testing.T) {
+	addrs := conformanceAddresses(t)
+	secp, id := addrs["secp256k1"], addrs["id 1000"]
+
+	opts := address.DecodeOptions{Protocols: []address.Protocol{address.ID, address.BLS}}
+	_, err := opts.NewFromString(id.String())
+	require.NoError(t, err)
+	_, err = opts.NewFromString(secp.String())
+	require.ErrorIs(t, err, address.ErrProtocolNotAllowed)
+	_, err = opts.NewFromBytes(secp.Bytes())
+	require.ErrorIs(t, err, address.ErrProtocolNotAllowed)
+
+	var buf bytes.Buffer
+	require.NoError(t, secp.MarshalCBOR(&buf))
+	var x address.Address
+	require.ErrorIs(t, opts.UnmarshalCBOR(bytes.NewReader(buf.Bytes()), &x), address.ErrProtocolNotAllowed)
+
+	// Secp256k1 addresses are 21 bytes long.
+	opts = address.DecodeOptions{MaxLength: 20}
+	_, err = opts.NewFromString(id.String())
+	require.NoError(t, err)
+	_, err = opts.NewFromString(secp.String())
+	require.ErrorIs(t, err, address.ErrInvalidLength)
+	_, err = opts.NewFromBytes(secp.Bytes())
+	require.ErrorIs(t, err, address.ErrInvalidLength)
+	require.Error(t, opts.UnmarshalCBOR(bytes.NewReader(buf.Bytes()), &x))
+	_, err = address.DecodeOptions{MaxLength: 21}.NewFromBytes(secp.Bytes())
+	require.NoError(t, err)
+
+	opts = address.DecodeOptions{Networks: []address.Network{address.Mainnet}}
+	_, err = opts.NewFromString("f01000")
+	require.NoError(t, err)
+	_, err = opts.NewFromString("t01000")
+	require.ErrorIs(t, err, address.ErrNetworkNotAllowed)
+	// Bytes carry no network.
+	_, err = opts.NewFromBytes(id.Bytes())
+	require.NoError(t, err)
+
+	// Invalid addresses are still rejected.
+	_, err = address.DecodeOptions{AllowUndef: true}.NewFromString("x01000")
+	require.ErrorIs(t, err, address.ErrUnknownNetwork)
+	_, err = address.DecodeOptions{AllowUndef: true}.NewFromBytes([]byte{0x09, 0x01})
+	require.ErrorIs(t, err, address.ErrUnknownProtocol)
+}
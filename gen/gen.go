@@ -9,6 +9,7 @@ import (
 func main() {
 	if err := gen.WriteTupleEncodersToFile("./cbor_gen.go", "address",
 		address.SubnetID{},
+		address.AddressBookEntry{},
 	); err != nil {
 		panic(err)
 	}
@@ -1,10 +1,14 @@
 package address
 
 import (
+	"encoding/json"
+	"fmt"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/multiformats/go-varint"
+	"golang.org/x/xerrors"
 )
 
 var id0, _ = NewIDAddress(0)
@@ -29,13 +33,22 @@ var UndefSubnetID = SubnetID{
 }
 
 // SubnetID represents the ID of a subnet
+//
+// Parent holds the path of the parent subnet in its canonical form, where
+// every actor segment is encoded with the MainnetPrefix regardless of
+// CurrentNetwork (see CanonicalString).
 type SubnetID struct {
 	Parent string
 	Actor  Address
 }
 
+// canonicalNetwork is the network used to encode the segments of a subnet
+// path in keys and payloads.
+const canonicalNetwork = Mainnet
+
+// Key returns the network-independent string used to index the subnet.
 func (id SubnetID) Key() string {
-	return id.String()
+	return id.CanonicalString()
 }
 
 // NewSubnetID generates the ID for a subnet from the networkName of its parent.
@@ -43,7 +56,7 @@ func (id SubnetID) Key() string {
 // It takes the parent name and adds the source address of the subnet actor that represents the subnet.
 func NewSubnetID(parentName SubnetID, SubnetActorAddr Address) SubnetID {
 	return SubnetID{
-		Parent: parentName.String(),
+		Parent: parentName.CanonicalString(),
 		Actor:  SubnetActorAddr,
 	}
 }
@@ -61,12 +74,155 @@ func SubnetIDFromString(str string) (SubnetID, error) {
 	if err != nil {
 		return UndefSubnetID, err
 	}
+	parent := strings.Join(s1[:len(s1)-1], SubnetSeparator)
+	if canonical, err := CanonicalSubnetString(parent); err == nil {
+		parent = canonical
+	}
 	return SubnetID{
-		Parent: strings.Join(s1[:len(s1)-1], SubnetSeparator),
+		Parent: parent,
 		Actor:  actor,
 	}, nil
 }
 
+// CanonicalSubnetString converts a subnet path written for any network into
+// its canonical, network-independent form.
+//
+// It can be used to migrate keys and parents built with the display form of
+// a subnet.
+func CanonicalSubnetString(str string) (string, error) {
+	return convertSubnetPath(str, canonicalNetwork)
+}
+
+// DisplaySubnetString converts a subnet path into its display form for the
+// given network.
+func DisplaySubnetString(str string, network Network) (string, error) {
+	return convertSubnetPath(str, network)
+}
+
+// convertSubnetPath re-encodes every actor segment of a subnet path for the
+// given network.
+func convertSubnetPath(str string, network Network) (string, error) {
+	segs := strings.Split(str, SubnetSeparator)
+	for i, seg := range segs {
+		if seg == "" || (i == 1 && SubnetSeparator+seg == RootStr) {
+			continue
+		}
+		a, err := NewFromString(seg)
+		if err != nil {
+			return "", xerrors.Errorf("decoding subnet segment %q: %w", seg, err)
+		}
+		if segs[i], err = encode(network, a); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(segs, SubnetSeparator), nil
+}
+
+// SubnetParseError is returned by ParseSubnetID when the input does not
+// follow the canonical subnet grammar:
+//
+//	subnet  = "/root" *( "/" segment )
+//	segment = ID address in its canonical string form
+//
+// with every segment using the same network prefix.
+type SubnetParseError struct {
+	// Input is the string that was being parsed.
+	Input string
+	// Pos is the byte offset in Input where the violation starts.
+	Pos int
+	// Err is the violation found.
+	Err error
+}
+
+func (e *SubnetParseError) Error() string {
+	return fmt.Sprintf("invalid subnet id %q at position %d: %s", e.Input, e.Pos, e.Err)
+}
+
+func (e *SubnetParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseSubnetID parses a subnet ID enforcing its canonical form.
+//
+// Unlike SubnetIDFromString, it rejects paths that are not rooted at RootStr,
+// empty segments, trailing separators, segments that are not canonical ID
+// addresses and segments using different network prefixes.
+func ParseSubnetID(str string) (SubnetID, error) {
+	if str == RootStr {
+		return RootSubnet, nil
+	}
+	if !strings.HasPrefix(str, RootStr+SubnetSeparator) {
+		return UndefSubnetID, &SubnetParseError{Input: str, Pos: 0, Err: ErrSubnetNotRooted}
+	}
+
+	var (
+		network byte
+		actor   Address
+		parent  strings.Builder
+	)
+	parent.WriteString(RootStr)
+	pos := len(RootStr) + 1
+	segs := strings.Split(str[pos:], SubnetSeparator)
+	for i, seg := range segs {
+		if seg == "" {
+			if i == len(segs)-1 {
+				return UndefSubnetID, &SubnetParseError{Input: str, Pos: pos - 1, Err: ErrSubnetTrailingSeparator}
+			}
+			return UndefSubnetID, &SubnetParseError{Input: str, Pos: pos, Err: ErrSubnetEmptySegment}
+		}
+		a, err := NewFromString(seg)
+		if err != nil {
+			return UndefSubnetID, &SubnetParseError{Input: str, Pos: pos, Err: xerrors.Errorf("%s: %w", err, ErrSubnetInvalidSegment)}
+		}
+		if a.Protocol() != ID {
+			return UndefSubnetID, &SubnetParseError{Input: str, Pos: pos, Err: ErrSubnetInvalidSegment}
+		}
+		if network == 0 {
+			network = seg[0]
+		} else if seg[0] != network {
+			return UndefSubnetID, &SubnetParseError{Input: str, Pos: pos, Err: ErrSubnetMixedNetwork}
+		}
+		// ID addresses can only be written in a non-canonical way by adding
+		// leading zeros to the ID.
+		if id, _ := IDFromAddress(a); seg[2:] != strconv.FormatUint(id, 10) {
+			return UndefSubnetID, &SubnetParseError{Input: str, Pos: pos, Err: ErrSubnetNonCanonicalSegment}
+		}
+		if i > 0 {
+			canonical, _ := encode(canonicalNetwork, actor)
+			parent.WriteString(SubnetSeparator + canonical)
+		}
+		actor = a
+		pos += len(seg) + 1
+	}
+
+	return SubnetID{
+		Parent: parent.String(),
+		Actor:  actor,
+	}, nil
+}
+
+// Validate checks that the subnet ID is in its canonical form.
+//
+// The returned error, if any, is a *SubnetParseError positioned over the
+// canonical string form of the ID.
+func (id SubnetID) Validate() error {
+	_, err := ParseSubnetID(id.CanonicalString())
+	return err
+}
+
+// Canonical returns the subnet ID with its parent converted to the canonical
+// form. It migrates IDs whose parent was built from a display string.
+func (id SubnetID) Canonical() (SubnetID, error) {
+	parent, err := CanonicalSubnetString(id.Parent)
+	if err != nil {
+		return UndefSubnetID, err
+	}
+	return SubnetID{
+		Parent: parent,
+		Actor:  id.Actor,
+	}, nil
+}
+
 // GetParent returns the ID of the parent network.
 func (id SubnetID) GetParent() (SubnetID, error) {
 	if id == RootSubnet {
@@ -87,8 +243,8 @@ func (id SubnetID) GetActor() Address {
 }
 
 func (id SubnetID) CommonParent(other SubnetID) (SubnetID, int) {
-	s1 := strings.Split(id.String(), SubnetSeparator)
-	s2 := strings.Split(other.String(), SubnetSeparator)
+	s1 := strings.Split(id.Key(), SubnetSeparator)
+	s2 := strings.Split(other.Key(), SubnetSeparator)
 	if len(s1) < len(s2) {
 		s1, s2 = s2, s1
 	}
@@ -114,8 +270,8 @@ func (id SubnetID) CommonParent(other SubnetID) (SubnetID, int) {
 }
 
 func (id SubnetID) Down(curr SubnetID) SubnetID {
-	s1 := strings.Split(id.String(), SubnetSeparator)
-	s2 := strings.Split(curr.String(), SubnetSeparator)
+	s1 := strings.Split(id.Key(), SubnetSeparator)
+	s2 := strings.Split(curr.Key(), SubnetSeparator)
 	// curr needs to be contained in id
 	if len(s2) >= len(s1) {
 		return UndefSubnetID
@@ -137,8 +293,8 @@ func (id SubnetID) Down(curr SubnetID) SubnetID {
 }
 
 func (id SubnetID) Up(curr SubnetID) SubnetID {
-	s1 := strings.Split(id.String(), SubnetSeparator)
-	s2 := strings.Split(curr.String(), SubnetSeparator)
+	s1 := strings.Split(id.Key(), SubnetSeparator)
+	s2 := strings.Split(curr.Key(), SubnetSeparator)
 	// curr needs to be contained in id
 	if len(s2) > len(s1) {
 		return UndefSubnetID
@@ -160,17 +316,68 @@ func (id SubnetID) Up(curr SubnetID) SubnetID {
 	return sn
 }
 
-// String returns the id in string form.
+// String returns the id in its display form, encoding every segment for
+// CurrentNetwork.
 func (id SubnetID) String() string {
 	if id == RootSubnet {
 		return RootStr
 	}
-	return strings.Join([]string{id.Parent, id.Actor.String()}, SubnetSeparator)
+	str := id.CanonicalString()
+	if display, err := DisplaySubnetString(str, CurrentNetwork); err == nil {
+		return display
+	}
+	return str
+}
+
+// CanonicalString returns the id in its canonical, network-independent form.
+//
+// This is the form used for keys and hierarchical address payloads.
+func (id SubnetID) CanonicalString() string {
+	if id == RootSubnet {
+		return RootStr
+	}
+	actor, err := encode(canonicalNetwork, id.Actor)
+	if err != nil {
+		panic(err) // same as Address.String
+	}
+	return strings.Join([]string{id.Parent, actor}, SubnetSeparator)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so subnet IDs can be
+// decoded from configuration formats such as YAML and TOML. It parses the
+// text with ParseSubnetID.
+func (id *SubnetID) UnmarshalText(text []byte) error {
+	sn, err := ParseSubnetID(string(text))
+	if err != nil {
+		return err
+	}
+	*id = sn
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns the display form
+// of the id.
+func (id SubnetID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// jsonSubnetID has the fields of SubnetID without its methods.
+type jsonSubnetID SubnetID
+
+// MarshalJSON encodes the id as an object of its fields. It is needed to keep
+// this form, as encoding/json would otherwise use MarshalText.
+func (id SubnetID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonSubnetID(id))
+}
+
+// UnmarshalJSON decodes an id encoded by MarshalJSON.
+func (id *SubnetID) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, (*jsonSubnetID)(id))
 }
 
 // Subnet returns subnet information for an address if any.
 func (a Address) Subnet() (SubnetID, error) {
-	if a.str[0] != Hierarchical {
+	if a.Protocol() != Hierarchical {
 		return UndefSubnetID, ErrNotHierarchical
 	}
 	snSize, _, err := varint.FromUvarint([]byte(a.str[1:2]))
@@ -180,9 +387,30 @@ func (a Address) Subnet() (SubnetID, error) {
 	return SubnetIDFromString(a.str[3 : snSize+3])
 }
 
+// CanonicalHCAddress rebuilds a hierarchical address so that its payload
+// holds the canonical form of its subnet. Addresses using other protocols
+// are returned as is.
+//
+// It migrates hierarchical addresses built when payloads depended on
+// CurrentNetwork.
+func CanonicalHCAddress(a Address) (Address, error) {
+	if a.Protocol() != Hierarchical {
+		return a, nil
+	}
+	sn, err := a.Subnet()
+	if err != nil {
+		return Undef, err
+	}
+	raw, err := a.RawAddr()
+	if err != nil {
+		return Undef, err
+	}
+	return NewHCAddress(sn, raw)
+}
+
 // RawAddr return the address without subnet context information.
 func (a Address) RawAddr() (Address, error) {
-	if a.str[0] != Hierarchical {
+	if a.Protocol() != Hierarchical {
 		return a, nil
 	}
 	snSize, _, err := varint.FromUvarint([]byte(a.str[1:2]))
@@ -193,10 +421,66 @@ func (a Address) RawAddr() (Address, error) {
 }
 
 func (a Address) PrettyPrint() string {
-	if a.str[0] != Hierarchical {
+	if a.Protocol() != Hierarchical {
 		return a.String()
 	}
 	sn, _ := a.Subnet()
 	raw, _ := a.RawAddr()
 	return string(sn.String() + HCAddrSeparator + raw.String())
 }
+
+// WithSubnet returns the address re-scoped to the given subnet.
+//
+// The subnet context of hierarchical addresses is replaced, other addresses
+// are wrapped in a hierarchical address for the subnet.
+func (a Address) WithSubnet(sn SubnetID) (Address, error) {
+	raw, err := a.RawAddr()
+	if err != nil {
+		return Undef, err
+	}
+	return NewHCAddress(sn, raw)
+}
+
+// InSubnet returns true if the address is a hierarchical address that
+// belongs to the given subnet.
+func (a Address) InSubnet(sn SubnetID) bool {
+	asn, err := a.Subnet()
+	if err != nil {
+		return false
+	}
+	return asn.Key() == sn.Key()
+}
+
+// EqualOption changes how Equal compares addresses.
+type EqualOption int
+
+const (
+	// IgnoreSubnet makes Equal compare the raw addresses only, regardless of
+	// the subnet context they may carry (see SameRawAddr).
+	IgnoreSubnet EqualOption = iota + 1
+)
+
+// Equal returns true if both addresses are the same, as compared with the
+// given options.
+func (a Address) Equal(o Address, opts ...EqualOption) bool {
+	for _, opt := range opts {
+		if opt == IgnoreSubnet {
+			return SameRawAddr(a, o)
+		}
+	}
+	return a == o
+}
+
+// SameRawAddr returns true if both addresses have the same raw address,
+// regardless of the subnet context they may carry.
+func SameRawAddr(a, b Address) bool {
+	ra, err := a.RawAddr()
+	if err != nil {
+		return false
+	}
+	rb, err := b.RawAddr()
+	if err != nil {
+		return false
+	}
+	return ra == rb
+}
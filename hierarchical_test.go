@@ -122,3 +122,245 @@ func testParentAndBottomUp(t *testing.T, from, to, parent string, exl int, botto
 	require.Equal(t, p, sparent)
 	require.Equal(t, exl, l)
 }
+
+func TestParseSubnetID(t *testing.T) {
+	address.CurrentNetwork = address.Mainnet
+	for _, s := range []string{"/root", "/root/f01", "/root/f01/f02", "/root/f0100/f0200/f03"} {
+		sn, err := address.ParseSubnetID(s)
+		require.NoError(t, err, s)
+		require.NoError(t, sn.Validate(), s)
+		loose, err := address.SubnetIDFromString(s)
+		require.NoError(t, err)
+		require.Equal(t, loose, sn)
+	}
+	_, err := address.ParseSubnetID("/root/t0100/t0200/t03")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		input string
+		pos   int
+		err   error
+	}{
+		{"", 0, address.ErrSubnetNotRooted},
+		{"/", 0, address.ErrSubnetNotRooted},
+		{"/rooted/f01", 0, address.ErrSubnetNotRooted},
+		{"root/f01", 0, address.ErrSubnetNotRooted},
+		{"/f01/f02", 0, address.ErrSubnetNotRooted},
+		{"/root/", 5, address.ErrSubnetTrailingSeparator},
+		{"/root/f01/", 9, address.ErrSubnetTrailingSeparator},
+		{"/root//f01", 6, address.ErrSubnetEmptySegment},
+		{"/root/f01//f02", 10, address.ErrSubnetEmptySegment},
+		{"/root/f01/f2gfvuyh7v2sx3patm5k23wdzmhyhtmqctasbr23y", 10, address.ErrSubnetInvalidSegment},
+		{"/root/f01/x01", 10, address.ErrSubnetInvalidSegment},
+		{"/root/f01/<empty>", 10, address.ErrSubnetInvalidSegment},
+		{"/root/f01/t02", 10, address.ErrSubnetMixedNetwork},
+		{"/root/f01/f002", 10, address.ErrSubnetNonCanonicalSegment},
+	}
+	for _, tc := range testCases {
+		_, err := address.ParseSubnetID(tc.input)
+		require.ErrorIs(t, err, tc.err, tc.input)
+		var perr *address.SubnetParseError
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, tc.input, perr.Input)
+		require.Equal(t, tc.pos, perr.Pos, tc.input)
+	}
+}
+
+func TestSubnetValidate(t *testing.T) {
+	address.CurrentNetwork = address.Mainnet
+	id1, _ := address.NewIDAddress(1)
+	actor, err := address.NewActorAddress([]byte("subnet"))
+	require.NoError(t, err)
+
+	require.NoError(t, address.RootSubnet.Validate())
+	require.NoError(t, address.NewSubnetID(address.RootSubnet, id1).Validate())
+	require.ErrorIs(t, address.UndefSubnetID.Validate(), address.ErrSubnetNotRooted)
+	require.ErrorIs(t, address.NewSubnetID(address.RootSubnet, actor).Validate(), address.ErrSubnetInvalidSegment)
+	require.ErrorIs(t, address.NewSubnetID(address.RootSubnet, address.Undef).Validate(), address.ErrSubnetInvalidSegment)
+	require.ErrorIs(t, address.SubnetID{Parent: "/root/", Actor: id1}.Validate(), address.ErrSubnetEmptySegment)
+
+	_, err = address.NewHCAddress(address.SubnetID{Parent: "/root//f01", Actor: id1}, id1)
+	require.ErrorIs(t, err, address.ErrSubnetEmptySegment)
+	_, err = address.NewHCAddress(address.UndefSubnetID, id1)
+	require.ErrorIs(t, err, address.ErrSubnetNotRooted)
+}
+
+func TestSubnetNetworkIndependence(t *testing.T) {
+	defer func(n address.Network) { address.CurrentNetwork = n }(address.CurrentNetwork)
+
+	id1, _ := address.NewIDAddress(1)
+	id2, _ := address.NewIDAddress(2)
+	id1000, _ := address.NewIDAddress(1000)
+
+	build := func(network address.Network) (address.SubnetID, address.Address, []byte) {
+		address.CurrentNetwork = network
+		sn := address.NewSubnetID(address.NewSubnetID(address.RootSubnet, id1), id2)
+		a, err := address.NewHCAddress(sn, id1000)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, sn.MarshalCBOR(&buf))
+		return sn, a, buf.Bytes()
+	}
+	snT, aT, cborT := build(address.Testnet)
+	snF, aF, cborF := build(address.Mainnet)
+
+	require.Equal(t, snT, snF)
+	require.Equal(t, aT, aF)
+	require.Equal(t, cborT, cborF)
+	require.Equal(t, "/root/f01/f02", snF.Key())
+	require.Equal(t, "/root/f01", snF.Parent)
+
+	address.CurrentNetwork = address.Testnet
+	require.Equal(t, "/root/f01/f02", snT.Key())
+	require.Equal(t, "/root/t01/t02", snT.String())
+	require.Equal(t, "/root/t01/t02:t01000", aT.PrettyPrint())
+	sn, err := address.SubnetIDFromString("/root/t01/t02")
+	require.NoError(t, err)
+	require.Equal(t, snT, sn)
+	sn, err = address.ParseSubnetID("/root/t01/t02")
+	require.NoError(t, err)
+	require.Equal(t, snT, sn)
+}
+
+func TestSubnetMigration(t *testing.T) {
+	defer func(n address.Network) { address.CurrentNetwork = n }(address.CurrentNetwork)
+	address.CurrentNetwork = address.Testnet
+
+	s, err := address.CanonicalSubnetString("/root/t01/t02")
+	require.NoError(t, err)
+	require.Equal(t, "/root/f01/f02", s)
+	s, err = address.DisplaySubnetString(s, address.Testnet)
+	require.NoError(t, err)
+	require.Equal(t, "/root/t01/t02", s)
+	s, err = address.CanonicalSubnetString(address.RootStr)
+	require.NoError(t, err)
+	require.Equal(t, address.RootStr, s)
+	_, err = address.CanonicalSubnetString("/root/t01/bad")
+	require.Error(t, err)
+
+	// Legacy subnet IDs kept the display form of their parent.
+	id2, _ := address.NewIDAddress(2)
+	legacy := address.SubnetID{Parent: "/root/t01", Actor: id2}
+	require.ErrorIs(t, legacy.Validate(), address.ErrSubnetMixedNetwork)
+	sn, err := legacy.Canonical()
+	require.NoError(t, err)
+	require.NoError(t, sn.Validate())
+	require.Equal(t, "/root/f01", sn.Parent)
+	require.Equal(t, "/root/t01/t02", sn.String())
+
+	// The payload of legacy hierarchical addresses held the display form of
+	// the subnet.
+	snB := []byte("/root/t01/t02")
+	addrB := id2.Bytes()
+	legacyHA, err := address.NewFromBytes(append(append([]byte{address.Hierarchical, byte(len(snB)), byte(len(addrB))}, snB...), addrB...))
+	require.NoError(t, err)
+	ha, err := address.CanonicalHCAddress(legacyHA)
+	require.NoError(t, err)
+	expected, err := address.NewHCAddress(sn, id2)
+	require.NoError(t, err)
+	require.Equal(t, expected, ha)
+	require.NotEqual(t, legacyHA, ha)
+
+	same, err := address.CanonicalHCAddress(id2)
+	require.NoError(t, err)
+	require.Equal(t, id2, same)
+}
+
+func TestHAddressArithmetic(t *testing.T) {
+	id1, _ := address.NewIDAddress(1)
+	id2, _ := address.NewIDAddress(2)
+	id1000, _ := address.NewIDAddress(1000)
+	net1 := address.NewSubnetID(address.RootSubnet, id1)
+	net2 := address.NewSubnetID(net1, id2)
+
+	a1, err := id1000.WithSubnet(net1)
+	require.NoError(t, err)
+	expected, err := address.NewHCAddress(net1, id1000)
+	require.NoError(t, err)
+	require.Equal(t, expected, a1)
+	require.True(t, a1.InSubnet(net1))
+	require.False(t, a1.InSubnet(net2))
+	require.False(t, id1000.InSubnet(net1))
+
+	// Rebasing replaces the subnet instead of nesting addresses.
+	a2, err := a1.WithSubnet(net2)
+	require.NoError(t, err)
+	require.True(t, a2.InSubnet(net2))
+	raw, err := a2.RawAddr()
+	require.NoError(t, err)
+	require.Equal(t, id1000, raw)
+
+	_, err = id1000.WithSubnet(address.UndefSubnetID)
+	require.Error(t, err)
+
+	require.True(t, address.SameRawAddr(a1, a2))
+	require.True(t, address.SameRawAddr(a1, id1000))
+	require.False(t, address.SameRawAddr(a1, id1))
+
+	require.True(t, a1.Equal(expected))
+	require.False(t, a1.Equal(a2))
+	require.False(t, id1000.Equal(a2))
+	require.True(t, a1.Equal(a2, address.IgnoreSubnet))
+	require.True(t, id1000.Equal(a2, address.IgnoreSubnet))
+	require.False(t, id1.Equal(a2, address.IgnoreSubnet))
+	require.True(t, address.Undef.Equal(address.Undef, address.IgnoreSubnet))
+}
+
+// hcPayload crafts the payload of a hierarchical address without going
+// through NewHCAddress.
+func hcPayload(subnet string, raw []byte) []byte {
+	return append(append([]byte{byte(len(subnet)), byte(len(raw))}, subnet...), raw...)
+}
+
+// hcString encodes a hierarchical payload as a string with a valid checksum.
+func hcString(payload []byte) string {
+	ingest := append([]byte{address.Hierarchical}, payload...)
+	cksm := address.Checksum(ingest)
+	return "f4" + address.AddressEncoding.WithPadding(-1).EncodeToString(append(payload, cksm...))
+}
+
+func TestHAddressInvariants(t *testing.T) {
+	id1000, _ := address.NewIDAddress(1000)
+	ha, err := address.NewHCAddress(address.RootSubnet, id1000)
+	require.NoError(t, err)
+
+	_, err = address.NewHCAddress(address.RootSubnet, ha)
+	require.ErrorIs(t, err, address.ErrNestedHierarchical)
+	_, err = address.NewHCAddress(address.RootSubnet, address.Undef)
+	require.ErrorIs(t, err, address.ErrUndefRawAddress)
+
+	testCases := []struct {
+		name    string
+		payload []byte
+		err     error
+	}{
+		{"nested", hcPayload("/root", ha.Bytes()), address.ErrNestedHierarchical},
+		{"undef", hcPayload("/root/f01", nil), address.ErrUndefRawAddress},
+		{"unknown protocol", hcPayload("/root", []byte{9, 1, 2}), address.ErrInvalidRawAddress},
+		{"bad id", hcPayload("/root", []byte{address.ID, 0x80}), address.ErrInvalidRawAddress},
+		{"short secp", hcPayload("/root", append([]byte{address.SECP256K1}, make([]byte, address.PayloadHashLength-1)...)), address.ErrInvalidRawAddress},
+		{"long bls", hcPayload("/root", append([]byte{address.BLS}, make([]byte, address.BlsPublicKeyBytes+1)...)), address.ErrInvalidRawAddress},
+		{"raw overflow", append([]byte{5, 10}, "/root\x00\xe8\x07"...), address.ErrInvalidLength},
+		{"too long", append(hcPayload("/root", id1000.Bytes()), make([]byte, address.HierarchicalLength)...), address.ErrInvalidLength},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := address.NewFromBytes(append([]byte{address.Hierarchical}, tc.payload...))
+			require.ErrorIs(t, err, tc.err)
+			if len(tc.payload) <= address.HierarchicalLength {
+				_, err = address.NewFromString(hcString(tc.payload))
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+
+	// A well-formed crafted payload is accepted through both paths.
+	payload := hcPayload("/root", id1000.Bytes())
+	a, err := address.NewFromBytes(append([]byte{address.Hierarchical}, payload...))
+	require.NoError(t, err)
+	require.Equal(t, ha, a)
+	a, err = address.NewFromString(hcString(payload))
+	require.NoError(t, err)
+	require.Equal(t, ha, a)
+}
@@ -0,0 +1,46 @@
+package ipld
+
+import (
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	"github.com/ipld/go-ipld-prime/schema"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// MarshalAddressDAGJSON returns the DAG-JSON encoding of a, as bytes:
+// {"/":{"bytes":"<unpadded base64>"}}. It is what converting the dag-cbor
+// output of MarshalCBOR to DAG-JSON produces, unlike MarshalJSON which
+// encodes the address string.
+func MarshalAddressDAGJSON(a address.Address) ([]byte, error) {
+	return ipld.Encode(AddressNode(a), dagjson.Encode)
+}
+
+// UnmarshalAddressDAGJSON decodes an address encoded by MarshalAddressDAGJSON.
+func UnmarshalAddressDAGJSON(b []byte) (address.Address, error) {
+	n, err := decodeDAGJSON(b, AddressPrototype)
+	if err != nil {
+		return address.Undef, err
+	}
+	return AddressFromNode(n)
+}
+
+// MarshalSubnetIDDAGJSON returns the DAG-JSON encoding of id, as a tuple of
+// its parent string and actor address bytes.
+func MarshalSubnetIDDAGJSON(id address.SubnetID) ([]byte, error) {
+	return ipld.Encode(SubnetIDNode(id), dagjson.Encode)
+}
+
+// UnmarshalSubnetIDDAGJSON decodes a subnet ID encoded by
+// MarshalSubnetIDDAGJSON.
+func UnmarshalSubnetIDDAGJSON(b []byte) (address.SubnetID, error) {
+	n, err := decodeDAGJSON(b, SubnetIDPrototype)
+	if err != nil {
+		return address.UndefSubnetID, err
+	}
+	return SubnetIDFromNode(n)
+}
+
+func decodeDAGJSON(b []byte, np schema.TypedPrototype) (ipld.Node, error) {
+	return ipld.DecodeUsingPrototype(b, dagjson.Decode, np.Representation())
+}
@@ -0,0 +1,78 @@
+package ipld_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/go-address"
+	addripld "github.com/filecoin-project/go-address/ipld"
+)
+
+// cborToDAGJSON converts the output of a MarshalCBOR method to DAG-JSON
+// through the untyped data model.
+func cborToDAGJSON(t *testing.T, m cbg.CBORMarshaler) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, m.MarshalCBOR(&buf))
+	n, err := ipld.Decode(buf.Bytes(), dagcbor.Decode)
+	require.NoError(t, err)
+	out, err := ipld.Encode(n, dagjson.Encode)
+	require.NoError(t, err)
+	return out
+}
+
+func TestAddressDAGJSON(t *testing.T) {
+	for _, a := range testAddresses(t) {
+		encoded, err := addripld.MarshalAddressDAGJSON(a)
+		require.NoError(t, err)
+
+		// Both paths and the spec agree.
+		require.Equal(t, cborToDAGJSON(t, &a), encoded)
+		require.Equal(t, `{"/":{"bytes":"`+base64.RawStdEncoding.EncodeToString(a.Bytes())+`"}}`, string(encoded))
+
+		decoded, err := addripld.UnmarshalAddressDAGJSON(encoded)
+		require.NoError(t, err)
+		require.Equal(t, a, decoded)
+	}
+
+	_, err := addripld.MarshalAddressDAGJSON(address.Undef)
+	require.Error(t, err)
+
+	for _, in := range []string{
+		`"f01024"`,
+		`{"/":{"bytes":""}}`,
+		`{"/":{"bytes":"CQA"}}`, // unknown protocol
+	} {
+		_, err := addripld.UnmarshalAddressDAGJSON([]byte(in))
+		require.Error(t, err, in)
+	}
+}
+
+func TestSubnetIDDAGJSON(t *testing.T) {
+	ids := []address.SubnetID{address.RootSubnet}
+	for _, a := range testAddresses(t)[:4] {
+		ids = append(ids, address.NewSubnetID(address.RootSubnet, a))
+	}
+
+	for _, id := range ids {
+		encoded, err := addripld.MarshalSubnetIDDAGJSON(id)
+		require.NoError(t, err)
+
+		require.Equal(t, cborToDAGJSON(t, &id), encoded)
+		require.Equal(t, `["`+id.Parent+`",{"/":{"bytes":"`+base64.RawStdEncoding.EncodeToString(id.Actor.Bytes())+`"}}]`, string(encoded))
+
+		decoded, err := addripld.UnmarshalSubnetIDDAGJSON(encoded)
+		require.NoError(t, err)
+		require.Equal(t, id, decoded)
+	}
+
+	_, err := addripld.UnmarshalSubnetIDDAGJSON([]byte(`{"Parent":"/root","Actor":{"/":{"bytes":"AAA"}}}`))
+	require.Error(t, err)
+}
@@ -0,0 +1,130 @@
+// Package ipld binds Address and SubnetID to the IPLD schema in
+// schema.ipldsch, so they can be encoded with go-ipld-prime codecs.
+//
+// Encoding an address or subnet ID with dag-cbor produces the same bytes as
+// their MarshalCBOR methods, and decoding validates addresses as
+// UnmarshalCBOR does.
+package ipld
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+	"github.com/ipld/go-ipld-prime/schema"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// Schema is the IPLD schema DSL of Address and SubnetID.
+//
+//go:embed schema.ipldsch
+var Schema []byte
+
+var (
+	// TypeSystem holds the types declared in Schema.
+	TypeSystem *schema.TypeSystem
+	// AddressType is the schema type of addresses.
+	AddressType schema.Type
+	// SubnetIDType is the schema type of subnet IDs.
+	SubnetIDType schema.Type
+
+	// AddressPrototype builds Address nodes bound to address.Address.
+	AddressPrototype schema.TypedPrototype
+	// SubnetIDPrototype builds SubnetID nodes bound to address.SubnetID.
+	SubnetIDPrototype schema.TypedPrototype
+)
+
+func init() {
+	ts, err := ipld.LoadSchemaBytes(Schema)
+	if err != nil {
+		panic(fmt.Sprintf("invalid address schema: %v", err)) // ok
+	}
+	TypeSystem = ts
+	AddressType = ts.TypeByName("Address")
+	SubnetIDType = ts.TypeByName("SubnetID")
+
+	AddressPrototype = bindnode.Prototype((*address.Address)(nil), AddressType, AddressConverter())
+	SubnetIDPrototype = bindnode.Prototype((*address.SubnetID)(nil), SubnetIDType, AddressConverter())
+}
+
+// AddressConverter binds address.Address to the Address bytes type. Pass it
+// to bindnode to use address.Address fields in other schemas.
+func AddressConverter() bindnode.Option {
+	return bindnode.TypedBytesConverter(&address.Address{}, addressFromBytes, addressToBytes)
+}
+
+func addressFromBytes(b []byte) (interface{}, error) {
+	a, err := address.NewFromBytes(b)
+	if err != nil {
+		return nil, err
+	}
+	if a == address.Undef {
+		return nil, xerrors.Errorf("cannot decode empty address")
+	}
+	return &a, nil
+}
+
+func addressToBytes(v interface{}) ([]byte, error) {
+	a, ok := v.(*address.Address)
+	if !ok {
+		return nil, xerrors.Errorf("expected *address.Address, got %T", v)
+	}
+	if *a == address.Undef {
+		return nil, xerrors.Errorf("cannot marshal undefined address")
+	}
+	return a.Bytes(), nil
+}
+
+// AddressNode returns the Address node of a.
+func AddressNode(a address.Address) schema.TypedNode {
+	return bindnode.Wrap(&a, AddressType, AddressConverter())
+}
+
+// SubnetIDNode returns the SubnetID node of id.
+func SubnetIDNode(id address.SubnetID) schema.TypedNode {
+	return bindnode.Wrap(&id, SubnetIDType, AddressConverter())
+}
+
+// AddressFromNode returns the address held by n, which is either an
+// Address node or its representation, such as a bytes node decoded by a
+// codec.
+func AddressFromNode(n datamodel.Node) (address.Address, error) {
+	v, err := unwrap(n, AddressPrototype)
+	if err != nil {
+		return address.Undef, err
+	}
+	return *v.(*address.Address), nil
+}
+
+// SubnetIDFromNode returns the subnet ID held by n, which is either a
+// SubnetID node or its tuple representation.
+func SubnetIDFromNode(n datamodel.Node) (address.SubnetID, error) {
+	v, err := unwrap(n, SubnetIDPrototype)
+	if err != nil {
+		return address.UndefSubnetID, err
+	}
+	return *v.(*address.SubnetID), nil
+}
+
+// unwrap returns the Go value bound to n, building it with np from the
+// representation of n if n is not already a node of np.
+func unwrap(n datamodel.Node, np schema.TypedPrototype) (interface{}, error) {
+	if tn, ok := n.(schema.TypedNode); ok {
+		if tn.Type() == np.Type() {
+			if v := bindnode.Unwrap(tn); v != nil {
+				return v, nil
+			}
+		}
+		n = tn.Representation()
+	}
+
+	nb := np.Representation().NewBuilder()
+	if err := datamodel.Copy(n, nb); err != nil {
+		return nil, xerrors.Errorf("building %s: %w", np.Type().Name(), err)
+	}
+	return bindnode.Unwrap(nb.Build()), nil
+}
@@ -0,0 +1,137 @@
+package ipld_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	addripld "github.com/filecoin-project/go-address/ipld"
+)
+
+func testAddresses(t *testing.T) []address.Address {
+	id, err := address.NewIDAddress(1024)
+	require.NoError(t, err)
+	secp, err := address.NewSecp256k1Address([]byte("secp256k1 public key"))
+	require.NoError(t, err)
+	actor, err := address.NewActorAddress([]byte("actor"))
+	require.NoError(t, err)
+	bls, err := address.NewBLSAddress(make([]byte, address.BlsPublicKeyBytes))
+	require.NoError(t, err)
+	hc, err := address.NewHCAddress(address.NewSubnetID(address.RootSubnet, id), secp)
+	require.NoError(t, err)
+	return []address.Address{id, secp, actor, bls, hc}
+}
+
+func TestAddressNode(t *testing.T) {
+	for _, a := range testAddresses(t) {
+		var expected bytes.Buffer
+		require.NoError(t, a.MarshalCBOR(&expected))
+
+		// Encoding matches cbor-gen.
+		encoded, err := ipld.Encode(addripld.AddressNode(a), dagcbor.Encode)
+		require.NoError(t, err)
+		require.Equal(t, expected.Bytes(), encoded)
+
+		// Decoding into the typed prototype or an untyped node.
+		n, err := ipld.DecodeUsingPrototype(encoded, dagcbor.Decode, addripld.AddressPrototype)
+		require.NoError(t, err)
+		got, err := addripld.AddressFromNode(n)
+		require.NoError(t, err)
+		require.Equal(t, a, got)
+
+		n, err = ipld.Decode(encoded, dagcbor.Decode)
+		require.NoError(t, err)
+		got, err = addripld.AddressFromNode(n)
+		require.NoError(t, err)
+		require.Equal(t, a, got)
+	}
+
+	_, err := ipld.Encode(addripld.AddressNode(address.Undef), dagcbor.Encode)
+	require.Error(t, err)
+
+	// Invalid addresses are rejected while decoding.
+	for _, b := range [][]byte{
+		{0x40},             // empty
+		{0x42, 0x01, 0x00}, // short secp256k1 payload
+		{0x42, 0x09, 0x00}, // unknown protocol
+	} {
+		_, err := ipld.DecodeUsingPrototype(b, dagcbor.Decode, addripld.AddressPrototype)
+		require.Error(t, err, b)
+	}
+	_, err = addripld.AddressFromNode(basicnode.NewString("f01"))
+	require.Error(t, err)
+}
+
+func TestSubnetIDNode(t *testing.T) {
+	ids := []address.SubnetID{address.RootSubnet}
+	for _, a := range testAddresses(t)[:4] {
+		sn := address.NewSubnetID(address.RootSubnet, a)
+		ids = append(ids, sn, address.NewSubnetID(sn, a))
+	}
+
+	for _, id := range ids {
+		var expected bytes.Buffer
+		require.NoError(t, id.MarshalCBOR(&expected))
+
+		encoded, err := ipld.Encode(addripld.SubnetIDNode(id), dagcbor.Encode)
+		require.NoError(t, err)
+		require.Equal(t, expected.Bytes(), encoded)
+
+		n, err := ipld.Decode(encoded, dagcbor.Decode)
+		require.NoError(t, err)
+		got, err := addripld.SubnetIDFromNode(n)
+		require.NoError(t, err)
+		require.Equal(t, id, got)
+
+		got, err = addripld.SubnetIDFromNode(addripld.SubnetIDNode(id))
+		require.NoError(t, err)
+		require.Equal(t, id, got)
+	}
+
+	// Subnet IDs without an actor cannot be encoded with cbor-gen either.
+	noActor := address.SubnetID{Parent: address.RootStr}
+	_, err := ipld.Encode(addripld.SubnetIDNode(noActor), dagcbor.Encode)
+	require.Error(t, err)
+	require.Error(t, noActor.MarshalCBOR(&bytes.Buffer{}))
+}
+
+// Address fields of other schemas can be bound to address.Address.
+func TestAddressConverter(t *testing.T) {
+	ts, err := ipld.LoadSchemaBytes(append(append([]byte{}, addripld.Schema...), []byte(`
+type Message struct {
+	To Address
+	From Address
+	Subnet SubnetID
+} representation tuple
+`)...))
+	require.NoError(t, err)
+
+	type message struct {
+		To     address.Address
+		From   address.Address
+		Subnet address.SubnetID
+	}
+	addrs := testAddresses(t)
+	msg := message{To: addrs[0], From: addrs[4], Subnet: address.NewSubnetID(address.RootSubnet, addrs[0])}
+
+	encoded, err := ipld.Encode(bindnode.Wrap(&msg, ts.TypeByName("Message"), addripld.AddressConverter()), dagcbor.Encode)
+	require.NoError(t, err)
+
+	var expected bytes.Buffer
+	expected.WriteByte(0x83)
+	require.NoError(t, msg.To.MarshalCBOR(&expected))
+	require.NoError(t, msg.From.MarshalCBOR(&expected))
+	require.NoError(t, msg.Subnet.MarshalCBOR(&expected))
+	require.Equal(t, expected.Bytes(), encoded)
+
+	np := bindnode.Prototype((*message)(nil), ts.TypeByName("Message"), addripld.AddressConverter())
+	n, err := ipld.DecodeUsingPrototype(encoded, dagcbor.Decode, np.Representation())
+	require.NoError(t, err)
+	require.Equal(t, msg, *bindnode.Unwrap(n).(*message))
+}
@@ -0,0 +1,89 @@
+package keys
+
+import (
+	"crypto/sha256"
+	"io"
+	"math/big"
+
+	secp256k1 "github.com/ipsn/go-secp256k1"
+	bls "github.com/kilic/bls12-381"
+	"golang.org/x/crypto/chacha20"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/xerrors"
+)
+
+// Generate generates a private key of the given type from the randomness
+// read from r, usually crypto/rand.Reader or a SeededReader.
+//
+// BLS keys are generated with the KeyGen procedure of the IETF BLS signature
+// draft over 32 bytes read from r, as done by Lotus wallets.
+func Generate(keyType string, r io.Reader) ([]byte, error) {
+	switch keyType {
+	case Secp256k1:
+		n := secp256k1.S256().Params().N
+		priv := make([]byte, PrivateKeyBytes)
+		for {
+			if _, err := io.ReadFull(r, priv); err != nil {
+				return nil, err
+			}
+			if validScalar(new(big.Int).SetBytes(priv), n) {
+				return priv, nil
+			}
+		}
+	case BLS:
+		ikm := make([]byte, 32)
+		if _, err := io.ReadFull(r, ikm); err != nil {
+			return nil, err
+		}
+		return BLSKeyGen(ikm), nil
+	default:
+		return nil, xerrors.Errorf("'%s': %w", keyType, ErrUnknownKeyType)
+	}
+}
+
+// BLSKeyGen derives a BLS private key from the input keying material ikm,
+// following the KeyGen procedure of the IETF BLS signature draft. The key is
+// returned little-endian.
+func BLSKeyGen(ikm []byte) []byte {
+	const l = 48
+	r := bls.NewG1().Q()
+	salt := []byte("BLS-SIG-KEYGEN-SALT-")
+	sk := new(big.Int)
+	for sk.Sign() == 0 {
+		h := sha256.Sum256(salt)
+		salt = h[:]
+
+		okm := make([]byte, l)
+		kdf := hkdf.New(sha256.New, append(append([]byte{}, ikm...), 0), salt, []byte{0, l})
+		if _, err := io.ReadFull(kdf, okm); err != nil {
+			// HKDF can output up to 255 blocks, this cannot happen.
+			panic(err) // ok
+		}
+		sk.SetBytes(okm).Mod(sk, r)
+	}
+	return reverse(sk.FillBytes(make([]byte, PrivateKeyBytes)))
+}
+
+// NewSeededReader returns a deterministic stream of random bytes expanded
+// from seed with ChaCha20. It is only as strong as the seed, and is meant to
+// reproduce keys for tests and development networks.
+func NewSeededReader(seed []byte) io.Reader {
+	key := sha256.Sum256(seed)
+	c, err := chacha20.NewUnauthenticatedCipher(key[:], make([]byte, chacha20.NonceSize))
+	if err != nil {
+		panic(err) // ok, the key and nonce sizes are fixed
+	}
+	return &seededReader{c: c}
+}
+
+type seededReader struct {
+	c *chacha20.Cipher
+}
+
+func (s *seededReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	s.c.XORKeyStream(p, p)
+	return len(p), nil
+}
@@ -0,0 +1,59 @@
+package keys_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address/keys"
+)
+
+func TestBLSKeyGen(t *testing.T) {
+	// Generated with blst from its KeyGen over the IKM 0x00..0x1f.
+	ikm := make([]byte, 32)
+	for i := range ikm {
+		ikm[i] = byte(i)
+	}
+	require.Equal(t, "5634db5df13ce91cde735366556fd174b4c111bc064e262ba3b037e3b70d3623", hex.EncodeToString(keys.BLSKeyGen(ikm)))
+}
+
+func TestGenerate(t *testing.T) {
+	for _, keyType := range []string{keys.Secp256k1, keys.BLS} {
+		priv, err := keys.Generate(keyType, rand.Reader)
+		require.NoError(t, err)
+		require.Len(t, priv, keys.PrivateKeyBytes)
+		_, err = keys.PublicKey(keyType, priv)
+		require.NoError(t, err)
+
+		// Seeded generation is reproducible.
+		a, err := keys.Generate(keyType, keys.NewSeededReader([]byte("seed")))
+		require.NoError(t, err)
+		b, err := keys.Generate(keyType, keys.NewSeededReader([]byte("seed")))
+		require.NoError(t, err)
+		require.Equal(t, a, b)
+		c, err := keys.Generate(keyType, keys.NewSeededReader([]byte("other seed")))
+		require.NoError(t, err)
+		require.NotEqual(t, a, c)
+	}
+
+	_, err := keys.Generate(keys.BLS, bytes.NewReader(nil))
+	require.ErrorIs(t, err, io.EOF)
+	_, err = keys.Generate("ed25519", rand.Reader)
+	require.ErrorIs(t, err, keys.ErrUnknownKeyType)
+}
+
+func TestExportLotusKey(t *testing.T) {
+	priv, err := keys.Generate(keys.Secp256k1, keys.NewSeededReader([]byte("seed")))
+	require.NoError(t, err)
+	ki := keys.KeyInfo{Type: keys.Secp256k1, PrivateKey: priv}
+
+	export, err := ki.ExportLotusKey()
+	require.NoError(t, err)
+	decoded, err := keys.DecodeLotusKey(export)
+	require.NoError(t, err)
+	require.Equal(t, ki, decoded)
+}
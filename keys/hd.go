@@ -0,0 +1,108 @@
+package keys
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+	"strconv"
+	"strings"
+
+	secp256k1 "github.com/ipsn/go-secp256k1"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/xerrors"
+)
+
+// HardenedOffset is added to the index of hardened BIP-32 path elements.
+const HardenedOffset = uint32(1) << 31
+
+// DefaultDerivationPath is the BIP-44 path of the first Filecoin account.
+const DefaultDerivationPath = "m/44'/461'/0'/0/0"
+
+// ParseDerivationPath parses a BIP-32 path such as m/44'/461'/0'/0/0.
+// Hardened elements are marked with ' or h.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	elems := strings.Split(path, "/")
+	if elems[0] != "m" {
+		return nil, xerrors.Errorf("derivation path %q must start with m", path)
+	}
+
+	out := make([]uint32, 0, len(elems)-1)
+	for _, e := range elems[1:] {
+		var offset uint32
+		if strings.HasSuffix(e, "'") || strings.HasSuffix(e, "h") {
+			offset = HardenedOffset
+			e = e[:len(e)-1]
+		}
+		i, err := strconv.ParseUint(e, 10, 31)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid element %q in derivation path %q", e, path)
+		}
+		out = append(out, uint32(i)+offset)
+	}
+	return out, nil
+}
+
+// DeriveFromMnemonic derives the secp256k1 private key at a BIP-32 path from
+// a BIP-39 mnemonic and its optional passphrase.
+func DeriveFromMnemonic(mnemonic, passphrase, path string) ([]byte, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(strings.Join(strings.Fields(mnemonic), " "), passphrase)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid mnemonic: %w", err)
+	}
+	p, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return DeriveFromSeed(seed, p)
+}
+
+// DeriveFromSeed derives the secp256k1 private key at a BIP-32 path from a
+// seed.
+func DeriveFromSeed(seed []byte, path []uint32) ([]byte, error) {
+	n := secp256k1.S256().Params().N
+
+	I := hmacSHA512([]byte("Bitcoin seed"), seed)
+	k, chain := new(big.Int).SetBytes(I[:32]), I[32:]
+	if !validScalar(k, n) {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	data := make([]byte, 37)
+	for _, i := range path {
+		if i >= HardenedOffset {
+			data[0] = 0
+			k.FillBytes(data[1:33])
+		} else {
+			copy(data[:33], compressedPublicKey(k))
+		}
+		binary.BigEndian.PutUint32(data[33:], i)
+
+		I := hmacSHA512(chain, data)
+		il := new(big.Int).SetBytes(I[:32])
+		if il.Cmp(n) >= 0 {
+			return nil, xerrors.Errorf("invalid child key at index %d: %w", i, ErrInvalidPrivateKey)
+		}
+		k.Add(k, il).Mod(k, n)
+		if k.Sign() == 0 {
+			return nil, xerrors.Errorf("invalid child key at index %d: %w", i, ErrInvalidPrivateKey)
+		}
+		chain = I[32:]
+	}
+
+	return k.FillBytes(make([]byte, PrivateKeyBytes)), nil
+}
+
+func compressedPublicKey(k *big.Int) []byte {
+	x, y := secp256k1.S256().ScalarBaseMult(k.FillBytes(make([]byte, PrivateKeyBytes)))
+	out := make([]byte, 33)
+	out[0] = 2 + byte(y.Bit(0))
+	x.FillBytes(out[1:])
+	return out
+}
+
+func hmacSHA512(key, data []byte) []byte {
+	h := hmac.New(sha512.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
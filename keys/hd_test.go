@@ -0,0 +1,75 @@
+package keys_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address/keys"
+)
+
+func TestParseDerivationPath(t *testing.T) {
+	p, err := keys.ParseDerivationPath("m/44'/461'/0h/0/7")
+	require.NoError(t, err)
+	h := keys.HardenedOffset
+	require.Equal(t, []uint32{44 + h, 461 + h, h, 0, 7}, p)
+
+	p, err = keys.ParseDerivationPath("m")
+	require.NoError(t, err)
+	require.Empty(t, p)
+
+	for _, bad := range []string{"", "44'/461'", "m/", "m/x", "m/-1", "m/2147483648", "m/1''"} {
+		_, err := keys.ParseDerivationPath(bad)
+		require.Error(t, err, bad)
+	}
+}
+
+func TestDeriveFromSeed(t *testing.T) {
+	// Test vector 1 from BIP-32.
+	seed := mustHex("000102030405060708090a0b0c0d0e0f")
+	testCases := []struct {
+		path string
+		priv string
+	}{
+		{"m", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	for _, tc := range testCases {
+		p, err := keys.ParseDerivationPath(tc.path)
+		require.NoError(t, err)
+		priv, err := keys.DeriveFromSeed(seed, p)
+		require.NoError(t, err)
+		require.Equal(t, tc.priv, hex.EncodeToString(priv), tc.path)
+	}
+}
+
+func TestDeriveFromMnemonic(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	priv, err := keys.DeriveFromMnemonic(mnemonic, "TREZOR", "m")
+	require.NoError(t, err)
+	// The master key of the seed given for this mnemonic in the BIP-39 test
+	// vectors.
+	expected, err := keys.DeriveFromSeed(mustHex("c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"), nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, priv)
+
+	// Extra whitespace is ignored.
+	again, err := keys.DeriveFromMnemonic(" abandon  abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about\n", "TREZOR", "m")
+	require.NoError(t, err)
+	require.Equal(t, priv, again)
+
+	account, err := keys.DeriveFromMnemonic(mnemonic, "", keys.DefaultDerivationPath)
+	require.NoError(t, err)
+	require.NotEqual(t, priv, account)
+	_, err = keys.PublicKey(keys.Secp256k1, account)
+	require.NoError(t, err)
+
+	_, err = keys.DeriveFromMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", "", keys.DefaultDerivationPath)
+	require.Error(t, err)
+	_, err = keys.DeriveFromMnemonic(mnemonic, "", "44'/461'")
+	require.Error(t, err)
+}
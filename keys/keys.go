@@ -0,0 +1,139 @@
+// Package keys derives Filecoin key pairs and their addresses offline.
+//
+// Private keys use the serialization of Lotus wallets: secp256k1 keys are
+// 32-byte big-endian scalars and BLS keys are 32-byte little-endian scalars.
+package keys
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/filecoin-project/go-crypto"
+	secp256k1 "github.com/ipsn/go-secp256k1"
+	bls "github.com/kilic/bls12-381"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+)
+
+const (
+	// Secp256k1 is the type of secp256k1 keys.
+	Secp256k1 = "secp256k1"
+	// BLS is the type of BLS keys.
+	BLS = "bls"
+)
+
+// PrivateKeyBytes is the length of a private key of any supported type.
+const PrivateKeyBytes = 32
+
+var (
+	// ErrUnknownKeyType is returned when encountering an unsupported key type.
+	ErrUnknownKeyType = errors.New("unknown key type")
+	// ErrInvalidPrivateKey is returned when a private key is not a valid scalar for its curve.
+	ErrInvalidPrivateKey = errors.New("invalid private key")
+)
+
+// PublicKey derives the public key of a private key of the given type.
+//
+// secp256k1 public keys are returned uncompressed (65 bytes) and BLS public
+// keys compressed (48 bytes), as expected by NewSecp256k1Address and
+// NewBLSAddress.
+func PublicKey(keyType string, priv []byte) ([]byte, error) {
+	if len(priv) != PrivateKeyBytes {
+		return nil, xerrors.Errorf("expected %d bytes, got %d: %w", PrivateKeyBytes, len(priv), ErrInvalidPrivateKey)
+	}
+
+	switch keyType {
+	case Secp256k1:
+		if !validScalar(new(big.Int).SetBytes(priv), secp256k1.S256().Params().N) {
+			return nil, ErrInvalidPrivateKey
+		}
+		return crypto.PublicKey(priv), nil
+	case BLS:
+		g1 := bls.NewG1()
+		sk := new(big.Int).SetBytes(reverse(priv))
+		if !validScalar(sk, g1.Q()) {
+			return nil, ErrInvalidPrivateKey
+		}
+		pub := g1.New()
+		g1.MulScalarBig(pub, g1.One(), sk)
+		return g1.ToCompressed(pub), nil
+	default:
+		return nil, xerrors.Errorf("'%s': %w", keyType, ErrUnknownKeyType)
+	}
+}
+
+// Address returns the address of a public key of the given type.
+func Address(keyType string, pub []byte) (address.Address, error) {
+	switch keyType {
+	case Secp256k1:
+		return address.NewSecp256k1Address(pub)
+	case BLS:
+		return address.NewBLSAddress(pub)
+	default:
+		return address.Undef, xerrors.Errorf("'%s': %w", keyType, ErrUnknownKeyType)
+	}
+}
+
+// KeyInfo is a private key in the format exported by Lotus wallets.
+type KeyInfo struct {
+	Type       string
+	PrivateKey []byte
+}
+
+// DecodeLotusKey decodes a key exported with `lotus wallet export`, the hex
+// encoding of the JSON form of a KeyInfo.
+func DecodeLotusKey(s string) (KeyInfo, error) {
+	b, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return KeyInfo{}, xerrors.Errorf("decoding hex: %w", err)
+	}
+	var ki KeyInfo
+	if err := json.Unmarshal(b, &ki); err != nil {
+		return KeyInfo{}, xerrors.Errorf("decoding key info: %w", err)
+	}
+	if ki.Type != Secp256k1 && ki.Type != BLS {
+		return KeyInfo{}, xerrors.Errorf("'%s': %w", ki.Type, ErrUnknownKeyType)
+	}
+	return ki, nil
+}
+
+// ExportLotusKey encodes the key in the format of `lotus wallet export`.
+func (ki KeyInfo) ExportLotusKey() (string, error) {
+	b, err := json.Marshal(ki)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// PublicKey derives the public key of the key.
+func (ki KeyInfo) PublicKey() ([]byte, error) {
+	return PublicKey(ki.Type, ki.PrivateKey)
+}
+
+// Address derives the address of the key.
+func (ki KeyInfo) Address() (address.Address, error) {
+	pub, err := ki.PublicKey()
+	if err != nil {
+		return address.Undef, err
+	}
+	return Address(ki.Type, pub)
+}
+
+func validScalar(k, order *big.Int) bool {
+	return k.Sign() > 0 && k.Cmp(order) < 0
+}
+
+// reverse returns a reversed copy of b, converting between little and big
+// endian.
+func reverse(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
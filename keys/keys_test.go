@@ -0,0 +1,96 @@
+package keys_test
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-address/keys"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestPublicKey(t *testing.T) {
+	testCases := []struct {
+		keyType string
+		priv    string
+		pub     string
+	}{
+		// The generator of secp256k1.
+		{keys.Secp256k1,
+			"0000000000000000000000000000000000000000000000000000000000000001",
+			"0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"},
+		// Generated with blst from its KeyGen over the IKM 0x00..0x1f.
+		{keys.BLS,
+			"5634db5df13ce91cde735366556fd174b4c111bc064e262ba3b037e3b70d3623",
+			"9112a0386a2340714ba0c6d2df235377a8679c3899d03e6ef04dba7a50ef49e5a1dc93105e9374e93ed301b63487e17c"},
+	}
+	for _, tc := range testCases {
+		pub, err := keys.PublicKey(tc.keyType, mustHex(tc.priv))
+		require.NoError(t, err)
+		require.Equal(t, tc.pub, hex.EncodeToString(pub))
+
+		a, err := keys.Address(tc.keyType, pub)
+		require.NoError(t, err)
+		var expected address.Address
+		if tc.keyType == keys.BLS {
+			expected, err = address.NewBLSAddress(pub)
+		} else {
+			expected, err = address.NewSecp256k1Address(pub)
+		}
+		require.NoError(t, err)
+		require.Equal(t, expected, a)
+	}
+}
+
+func TestInvalidPrivateKey(t *testing.T) {
+	for _, keyType := range []string{keys.Secp256k1, keys.BLS} {
+		_, err := keys.PublicKey(keyType, make([]byte, keys.PrivateKeyBytes))
+		require.ErrorIs(t, err, keys.ErrInvalidPrivateKey)
+		_, err = keys.PublicKey(keyType, make([]byte, keys.PrivateKeyBytes-1))
+		require.ErrorIs(t, err, keys.ErrInvalidPrivateKey)
+	}
+
+	// secp256k1 keys are big-endian, BLS keys little-endian, both must be
+	// below the order of their group.
+	secpOrder := "fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141"
+	_, err := keys.PublicKey(keys.Secp256k1, mustHex(secpOrder))
+	require.ErrorIs(t, err, keys.ErrInvalidPrivateKey)
+	blsOrderLE := "01000000fffffffffe5bfeff02a4bd5305d8a10908d83933487d9d2953a7ed73"
+	_, err = keys.PublicKey(keys.BLS, mustHex(blsOrderLE))
+	require.ErrorIs(t, err, keys.ErrInvalidPrivateKey)
+
+	_, err = keys.PublicKey("ed25519", make([]byte, keys.PrivateKeyBytes))
+	require.ErrorIs(t, err, keys.ErrUnknownKeyType)
+}
+
+func TestDecodeLotusKey(t *testing.T) {
+	priv := mustHex("5634db5df13ce91cde735366556fd174b4c111bc064e262ba3b037e3b70d3623")
+	export := hex.EncodeToString([]byte(fmt.Sprintf(`{"Type":"bls","PrivateKey":"%s"}`, base64.StdEncoding.EncodeToString(priv))))
+
+	ki, err := keys.DecodeLotusKey(export + "\n")
+	require.NoError(t, err)
+	require.Equal(t, keys.BLS, ki.Type)
+	require.Equal(t, priv, ki.PrivateKey)
+
+	a, err := ki.Address()
+	require.NoError(t, err)
+	expected, err := address.NewBLSAddress(mustHex("9112a0386a2340714ba0c6d2df235377a8679c3899d03e6ef04dba7a50ef49e5a1dc93105e9374e93ed301b63487e17c"))
+	require.NoError(t, err)
+	require.Equal(t, expected, a)
+
+	_, err = keys.DecodeLotusKey("zz")
+	require.Error(t, err)
+	_, err = keys.DecodeLotusKey(hex.EncodeToString([]byte(`{"Type":"ed25519","PrivateKey":""}`)))
+	require.ErrorIs(t, err, keys.ErrUnknownKeyType)
+}
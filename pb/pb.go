@@ -0,0 +1,73 @@
+// Package pb defines protobuf messages for Address and SubnetID, generated
+// from address.proto, so they can be passed between services without
+// re-parsing their string form.
+//
+// Messages are validated when converted back to the native types.
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative address.proto
+
+import (
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+)
+
+// AddressMessage returns the message of a, with its string form cached for
+// the current network.
+func AddressMessage(a address.Address) *Address {
+	m := &Address{Bytes: a.Bytes()}
+	if a != address.Undef {
+		s := a.String()
+		m.Text = &s
+	}
+	return m
+}
+
+// AddressFromMessage returns the address held by m, which must be valid. A nil
+// message holds Undef. The cached string, if any, must be the string of the
+// address for either network.
+func AddressFromMessage(m *Address) (address.Address, error) {
+	a, err := address.NewFromBytes(m.GetBytes())
+	if err != nil {
+		return address.Undef, err
+	}
+	if m.GetText() == "" {
+		return a, nil
+	}
+
+	text, err := address.NewFromString(m.GetText())
+	if err != nil {
+		return address.Undef, xerrors.Errorf("invalid cached string: %w", err)
+	}
+	if text != a {
+		return address.Undef, xerrors.Errorf("cached string %s does not match the address bytes", m.GetText())
+	}
+	return a, nil
+}
+
+// SubnetIDMessage returns the message of id.
+func SubnetIDMessage(id address.SubnetID) *SubnetID {
+	return &SubnetID{
+		Parent: id.Parent,
+		Actor:  AddressMessage(id.Actor),
+	}
+}
+
+// SubnetIDFromMessage returns the subnet ID held by m, which must be in its
+// canonical form (see address.SubnetID.Validate).
+func SubnetIDFromMessage(m *SubnetID) (address.SubnetID, error) {
+	if m == nil {
+		return address.UndefSubnetID, xerrors.Errorf("missing subnet id")
+	}
+	actor, err := AddressFromMessage(m.Actor)
+	if err != nil {
+		return address.UndefSubnetID, xerrors.Errorf("invalid subnet actor: %w", err)
+	}
+
+	id := address.SubnetID{Parent: m.Parent, Actor: actor}
+	if err := id.Validate(); err != nil {
+		return address.UndefSubnetID, err
+	}
+	return id, nil
+}
@@ -0,0 +1,113 @@
+package pb_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-address/pb"
+)
+
+func testAddresses(t *testing.T) []address.Address {
+	id, err := address.NewIDAddress(1024)
+	require.NoError(t, err)
+	secp, err := address.NewSecp256k1Address([]byte("secp256k1 public key"))
+	require.NoError(t, err)
+	actor, err := address.NewActorAddress([]byte("actor"))
+	require.NoError(t, err)
+	bls, err := address.NewBLSAddress(make([]byte, address.BlsPublicKeyBytes))
+	require.NoError(t, err)
+	hc, err := address.NewHCAddress(address.NewSubnetID(address.RootSubnet, id), secp)
+	require.NoError(t, err)
+	return []address.Address{address.Undef, id, secp, actor, bls, hc}
+}
+
+func TestAddressMessage(t *testing.T) {
+	for _, a := range testAddresses(t) {
+		m := pb.AddressMessage(a)
+		require.Equal(t, a.Bytes(), m.GetBytes())
+		if a != address.Undef {
+			require.Equal(t, a.String(), m.GetText())
+		}
+
+		b, err := proto.Marshal(m)
+		require.NoError(t, err)
+		var decoded pb.Address
+		require.NoError(t, proto.Unmarshal(b, &decoded))
+
+		got, err := pb.AddressFromMessage(&decoded)
+		require.NoError(t, err)
+		require.Equal(t, a, got)
+
+		// The cached string is optional.
+		got, err = pb.AddressFromMessage(&pb.Address{Bytes: a.Bytes()})
+		require.NoError(t, err)
+		require.Equal(t, a, got)
+	}
+
+	got, err := pb.AddressFromMessage(nil)
+	require.NoError(t, err)
+	require.Equal(t, address.Undef, got)
+
+	// The cached string can be of any network.
+	id, err := address.NewIDAddress(1024)
+	require.NoError(t, err)
+	for _, s := range []string{"f01024", "t01024"} {
+		got, err = pb.AddressFromMessage(&pb.Address{Bytes: id.Bytes(), Text: proto.String(s)})
+		require.NoError(t, err)
+		require.Equal(t, id, got)
+	}
+}
+
+func TestAddressMessageInvalid(t *testing.T) {
+	id, err := address.NewIDAddress(1024)
+	require.NoError(t, err)
+
+	for _, m := range []*pb.Address{
+		{Bytes: []byte{0x09, 0x01}},
+		{Bytes: []byte{0x01, 0x01}},
+		{Bytes: id.Bytes(), Text: proto.String("f01025")},
+		{Bytes: id.Bytes(), Text: proto.String("f0x")},
+		{Text: proto.String("f01024")},
+	} {
+		_, err := pb.AddressFromMessage(m)
+		require.Error(t, err, m)
+	}
+}
+
+func TestSubnetIDMessage(t *testing.T) {
+	id, err := address.NewIDAddress(1024)
+	require.NoError(t, err)
+	id2, err := address.NewIDAddress(2048)
+	require.NoError(t, err)
+
+	for _, sn := range []address.SubnetID{
+		address.RootSubnet,
+		address.NewSubnetID(address.RootSubnet, id),
+		address.NewSubnetID(address.NewSubnetID(address.RootSubnet, id), id2),
+	} {
+		b, err := proto.Marshal(pb.SubnetIDMessage(sn))
+		require.NoError(t, err)
+		var decoded pb.SubnetID
+		require.NoError(t, proto.Unmarshal(b, &decoded))
+
+		got, err := pb.SubnetIDFromMessage(&decoded)
+		require.NoError(t, err)
+		require.Equal(t, sn, got)
+	}
+
+	actor, err := address.NewActorAddress([]byte("actor"))
+	require.NoError(t, err)
+	for _, m := range []*pb.SubnetID{
+		nil,
+		{Parent: address.RootStr},
+		{Parent: "/other", Actor: pb.AddressMessage(id)},
+		{Parent: address.RootStr, Actor: pb.AddressMessage(actor)},
+		{Parent: address.RootStr, Actor: &pb.Address{Bytes: []byte{0x09, 0x01}}},
+	} {
+		_, err := pb.SubnetIDFromMessage(m)
+		require.Error(t, err, m)
+	}
+}
@@ -0,0 +1,78 @@
+package address
+
+import (
+	"sort"
+	"strings"
+)
+
+// Compare returns an integer comparing two addresses: 0 if a == b, -1 if a
+// sorts before b and +1 otherwise.
+//
+// Addresses are ordered by protocol, then numerically by ID for ID addresses
+// and bytewise by payload for the others. This is the byte order of Bytes(),
+// except that IDs are compared by value rather than by their varint
+// encoding. Undef sorts first.
+func Compare(a, b Address) int {
+	switch {
+	case a == b:
+		return 0
+	case a == Undef:
+		return -1
+	case b == Undef:
+		return 1
+	}
+
+	if a.str[0] == ID && b.str[0] == ID {
+		x, y := idValue(a), idValue(b)
+		if x < y {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a.str, b.str)
+}
+
+// idValue decodes the ID of a valid ID address without allocating.
+func idValue(a Address) uint64 {
+	var v uint64
+	for i := 1; i < len(a.str); i++ {
+		v |= uint64(a.str[i]&0x7f) << (7 * uint(i-1))
+	}
+	return v
+}
+
+// AddressSlice attaches the methods of sort.Interface to []Address, sorting
+// in the order of Compare.
+type AddressSlice []Address
+
+func (p AddressSlice) Len() int           { return len(p) }
+func (p AddressSlice) Less(i, j int) bool { return Compare(p[i], p[j]) < 0 }
+func (p AddressSlice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// Sort is a convenience method: p.Sort() calls sort.Sort(p).
+func (p AddressSlice) Sort() { sort.Sort(p) }
+
+// Search returns the result of applying SearchAddresses to the receiver and a.
+func (p AddressSlice) Search(a Address) int { return SearchAddresses(p, a) }
+
+// SortAddresses sorts a slice of addresses in the order of Compare.
+func SortAddresses(addrs []Address) {
+	sort.Sort(AddressSlice(addrs))
+}
+
+// SearchAddresses searches for a in a sorted slice of addresses and returns
+// the index of a, or the index where a would be inserted if it is not
+// present (it could be len(addrs)). The slice must be sorted in the order of
+// Compare.
+func SearchAddresses(addrs []Address, a Address) int {
+	return sort.Search(len(addrs), func(i int) bool {
+		return Compare(addrs[i], a) >= 0
+	})
+}
+
+// ContainsAddress reports whether a is in a slice of addresses sorted in the
+// order of Compare.
+func ContainsAddress(addrs []Address, a Address) bool {
+	i := SearchAddresses(addrs, a)
+	return i < len(addrs) && addrs[i] == a
+}
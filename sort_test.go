@@ -0,0 +1,70 @@
+package address_test
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func TestCompare(t *testing.T) {
+	id := func(v uint64) address.Address {
+		a, err := address.NewIDAddress(v)
+		require.NoError(t, err)
+		return a
+	}
+
+	// IDs compare by value, even where their varints do not.
+	require.Equal(t, 1, bytes.Compare(id(255).Bytes(), id(256).Bytes()))
+	require.Equal(t, -1, address.Compare(id(255), id(256)))
+	require.Equal(t, 1, address.Compare(id(256), id(255)))
+	require.Equal(t, 0, address.Compare(id(256), id(256)))
+
+	require.Equal(t, -1, address.Compare(address.Undef, id(0)))
+	require.Equal(t, 1, address.Compare(id(0), address.Undef))
+	require.Equal(t, 0, address.Compare(address.Undef, address.Undef))
+
+	// Other addresses compare as their bytes, so by protocol first.
+	addrs := testSet(t, 50)
+	for i := 0; i < 1000; i++ {
+		a, b := addrs[rand.Intn(len(addrs))], addrs[rand.Intn(len(addrs))]
+		if a.Protocol() == address.ID && b.Protocol() == address.ID {
+			continue
+		}
+		require.Equal(t, bytes.Compare(a.Bytes(), b.Bytes()), address.Compare(a, b), "%s %s", a, b)
+	}
+}
+
+func TestSortAddresses(t *testing.T) {
+	// testSet returns addresses in set order, the order of Compare.
+	sorted := append([]address.Address{address.Undef}, testSet(t, 200)...)
+
+	shuffled := append([]address.Address(nil), sorted...)
+	rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	address.SortAddresses(shuffled)
+	require.Equal(t, sorted, shuffled)
+
+	rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	address.AddressSlice(shuffled).Sort()
+	require.Equal(t, sorted, shuffled)
+	require.True(t, sort.IsSorted(address.AddressSlice(shuffled)))
+
+	for i, a := range sorted {
+		require.Equal(t, i, address.SearchAddresses(sorted, a))
+		require.Equal(t, i, address.AddressSlice(sorted).Search(a))
+		require.True(t, address.ContainsAddress(sorted, a))
+	}
+
+	// Missing addresses are placed after the addresses that sort before.
+	missing, err := address.NewActorAddress([]byte("missing"))
+	require.NoError(t, err)
+	i := address.SearchAddresses(sorted, missing)
+	require.Equal(t, -1, address.Compare(sorted[i-1], missing))
+	require.Equal(t, 1, address.Compare(sorted[i], missing))
+	require.False(t, address.ContainsAddress(sorted, missing))
+	require.Equal(t, 0, address.SearchAddresses(nil, missing))
+}
@@ -0,0 +1,79 @@
+package address_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+)
+
+func TestAddressText(t *testing.T) {
+	for name, a := range conformanceAddresses(t) {
+		text, err := a.MarshalText()
+		require.NoError(t, err, name)
+		require.Equal(t, a.String(), string(text), name)
+
+		var x address.Address
+		require.NoError(t, x.UnmarshalText(text), name)
+		require.Equal(t, a, x, name)
+
+		if a.Protocol() == address.Hierarchical {
+			x = address.Undef
+			require.NoError(t, x.UnmarshalText([]byte(a.PrettyPrint())), name)
+			require.Equal(t, a, x, name)
+		}
+	}
+
+	var x address.Address
+	for _, s := range []string{"f0x", "x01000", "/root/f0x:f01000", "/root:f0x", "/root/f01000:/root/f01000:f01000"} {
+		err := x.UnmarshalText([]byte(s))
+		var perr *address.AddressParseError
+		require.ErrorAs(t, err, &perr, s)
+		require.Equal(t, s, perr.Input)
+	}
+	require.ErrorIs(t, x.UnmarshalText([]byte("x01000")), address.ErrUnknownNetwork)
+}
+
+func TestSubnetIDText(t *testing.T) {
+	id, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	sn := address.NewSubnetID(address.NewSubnetID(address.RootSubnet, id), id)
+
+	for _, s := range []address.SubnetID{address.RootSubnet, sn} {
+		text, err := s.MarshalText()
+		require.NoError(t, err)
+		require.Equal(t, s.String(), string(text))
+
+		var x address.SubnetID
+		require.NoError(t, x.UnmarshalText(text))
+		require.Equal(t, s, x)
+	}
+
+	var x address.SubnetID
+	var perr *address.SubnetParseError
+	require.ErrorAs(t, x.UnmarshalText([]byte("/other/f01000")), &perr)
+	require.ErrorIs(t, perr, address.ErrSubnetNotRooted)
+}
+
+func TestSubnetIDJSON(t *testing.T) {
+	id, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	sn := address.NewSubnetID(address.RootSubnet, id)
+
+	// Subnet IDs keep their object form, while addresses are strings.
+	b, err := json.Marshal(sn)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Parent":"/root","Actor":"`+id.String()+`"}`, string(b))
+
+	var x address.SubnetID
+	require.NoError(t, json.Unmarshal(b, &x))
+	require.Equal(t, sn, x)
+
+	// Text marshalling also allows them as map keys.
+	m := map[address.SubnetID]address.Address{sn: id}
+	b, err = json.Marshal(m)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"`+sn.String()+`":"`+id.String()+`"}`, string(b))
+}
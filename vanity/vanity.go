@@ -0,0 +1,354 @@
+// Package vanity searches for addresses whose string form starts or ends with
+// a chosen pattern.
+//
+// Keys and actor seeds are drawn at random until the base32 part of the
+// address (after the network and protocol prefix, e.g. "f1") matches. Every
+// character of the pattern multiplies the expected number of attempts by up
+// to 32, see ExpectedAttempts.
+package vanity
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-address/keys"
+)
+
+const (
+	// Secp256k1 searches secp256k1 keys.
+	Secp256k1 = keys.Secp256k1
+	// BLS searches BLS keys.
+	BLS = keys.BLS
+	// Actor searches seeds of actor addresses, as given to NewActorAddress.
+	Actor = "actor"
+)
+
+// SeedBytes is the length of the actor seeds drawn by Search.
+const SeedBytes = 32
+
+// Alphabet holds the characters addresses are encoded with.
+const Alphabet = "abcdefghijklmnopqrstuvwxyz234567"
+
+// DefaultProgressInterval is how often progress is reported if
+// Options.ProgressInterval is not set.
+const DefaultProgressInterval = time.Second
+
+var (
+	// ErrInvalidPattern is returned for patterns that no address can match.
+	ErrInvalidPattern = errors.New("invalid vanity pattern")
+	// ErrUnknownType is returned for unsupported search types.
+	ErrUnknownType = errors.New("unknown vanity search type")
+)
+
+// Options configure a search.
+type Options struct {
+	// Type is the type of address searched: Secp256k1, BLS or Actor.
+	Type string
+	// Prefix and Suffix are the base32 characters the address must start
+	// and end with, after its network and protocol prefix. At least one of
+	// them must be set.
+	Prefix string
+	Suffix string
+	// Workers is the number of goroutines searching in parallel. It
+	// defaults to the number of CPUs.
+	Workers int
+	// Rand seeds the random stream of every worker. It defaults to
+	// crypto/rand.Reader.
+	Rand io.Reader
+	// Progress, if set, is called every ProgressInterval while searching.
+	// Calls are never concurrent.
+	Progress         func(Progress)
+	ProgressInterval time.Duration
+}
+
+// Progress reports the state of a running search.
+type Progress struct {
+	Attempts uint64
+	Elapsed  time.Duration
+	// Expected is the expected number of attempts to find a match.
+	Expected float64
+}
+
+// Rate returns the number of attempts per second so far.
+func (p Progress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Attempts) / p.Elapsed.Seconds()
+}
+
+// Result is a matching address with the secret it was derived from.
+type Result struct {
+	Type    string
+	Address address.Address
+	// PrivateKey and PublicKey are set for key searches, in the format of
+	// the keys package.
+	PrivateKey []byte
+	PublicKey  []byte
+	// Seed is set for actor searches.
+	Seed []byte
+	// Attempts is the number of candidates tried by all workers.
+	Attempts uint64
+}
+
+// encodedLength returns the number of base32 characters of an address of
+// the given type after its prefix, and the number of bits held by the last
+// one.
+func encodedLength(typ string) (int, int, error) {
+	var n int
+	switch typ {
+	case Secp256k1, Actor:
+		n = address.PayloadHashLength
+	case BLS:
+		n = address.BlsPublicKeyBytes
+	default:
+		return 0, 0, xerrors.Errorf("'%s': %w", typ, ErrUnknownType)
+	}
+	bits := (n + address.ChecksumHashLength) * 8
+	chars := (bits + 4) / 5
+	return chars, bits - (chars-1)*5, nil
+}
+
+// ExpectedAttempts returns the expected number of attempts to find an
+// address of the given type matching prefix and suffix. It returns an
+// ErrInvalidPattern error if no address can match.
+//
+// Characters are assumed uniformly distributed, except where the encoding
+// restricts them: the last character only holds the remaining bits of the
+// checksum, and the first character of BLS addresses holds the flag bits of
+// the compressed public key, so it is one of q to x. The estimate for BLS
+// prefixes is approximate as the next bits are not uniform either.
+func ExpectedAttempts(typ, prefix, suffix string) (float64, error) {
+	chars, lastBits, err := encodedLength(typ)
+	if err != nil {
+		return 0, err
+	}
+	if prefix == "" && suffix == "" {
+		return 0, xerrors.Errorf("empty prefix and suffix: %w", ErrInvalidPattern)
+	}
+	if len(prefix) > chars || len(suffix) > chars {
+		return 0, xerrors.Errorf("pattern longer than the %d characters of %s addresses: %w", chars, typ, ErrInvalidPattern)
+	}
+
+	// Bits fixed at every position, and the positions set by the pattern.
+	fixed := make([]int, chars)
+	for i := range fixed {
+		fixed[i] = -1
+	}
+	set := func(pos int, c byte) error {
+		v := strings.IndexByte(Alphabet, c)
+		if v < 0 {
+			return xerrors.Errorf("'%c' is not in the base32 alphabet %s: %w", c, Alphabet, ErrInvalidPattern)
+		}
+		if fixed[pos] >= 0 && fixed[pos] != v {
+			return xerrors.Errorf("prefix and suffix overlap with different characters: %w", ErrInvalidPattern)
+		}
+		fixed[pos] = v
+		return nil
+	}
+	for i := 0; i < len(prefix); i++ {
+		if err := set(i, prefix[i]); err != nil {
+			return 0, err
+		}
+	}
+	for i := 0; i < len(suffix); i++ {
+		if err := set(chars-len(suffix)+i, suffix[i]); err != nil {
+			return 0, err
+		}
+	}
+
+	bits := 0
+	for pos, v := range fixed {
+		if v < 0 {
+			continue
+		}
+		switch {
+		case pos == chars-1:
+			if v&(1<<(5-lastBits)-1) != 0 {
+				return 0, xerrors.Errorf("%s addresses cannot end with '%c': %w", typ, Alphabet[v], ErrInvalidPattern)
+			}
+			bits += lastBits
+		case pos == 0 && typ == BLS:
+			// The compression and infinity flags are always 1 and 0.
+			if v>>3 != 2 {
+				return 0, xerrors.Errorf("%s addresses cannot start with '%c': %w", typ, Alphabet[v], ErrInvalidPattern)
+			}
+			bits += 3
+		default:
+			bits += 5
+		}
+	}
+	return math.Exp2(float64(bits)), nil
+}
+
+// Search draws addresses of the given type until one matches the options,
+// using all workers in parallel. It returns the context error if ctx is done
+// first.
+func Search(ctx context.Context, opts Options) (*Result, error) {
+	expected, err := ExpectedAttempts(opts.Type, opts.Prefix, opts.Suffix)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	src := opts.Rand
+	if src == nil {
+		src = rand.Reader
+	}
+	interval := opts.ProgressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		attempts uint64
+		once     sync.Once
+		found    *Result
+		wg       sync.WaitGroup
+		errs     = make(chan error, workers)
+	)
+	for i := 0; i < workers; i++ {
+		seed := make([]byte, 32)
+		if _, err := io.ReadFull(src, seed); err != nil {
+			return nil, xerrors.Errorf("seeding worker: %w", err)
+		}
+
+		wg.Add(1)
+		go func(r io.Reader) {
+			defer wg.Done()
+			res, err := search(ctx, opts, r, &attempts)
+			if err != nil {
+				errs <- err
+				cancel()
+				return
+			}
+			if res != nil {
+				once.Do(func() {
+					found = res
+					cancel()
+				})
+			}
+		}(keys.NewSeededReader(seed))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			if opts.Progress != nil {
+				opts.Progress(Progress{
+					Attempts: atomic.LoadUint64(&attempts),
+					Elapsed:  time.Since(start),
+					Expected: expected,
+				})
+			}
+		case <-done:
+			break loop
+		}
+	}
+
+	if found != nil {
+		found.Attempts = atomic.LoadUint64(&attempts)
+		return found, nil
+	}
+	select {
+	case err := <-errs:
+		return nil, err
+	default:
+	}
+	return nil, ctx.Err()
+}
+
+// search runs a single worker until it finds a match or ctx is done, in which
+// case it returns nil.
+func search(ctx context.Context, opts Options, r io.Reader, attempts *uint64) (*Result, error) {
+	// Checking the context and counting on every attempt would slow down
+	// the fastest searches.
+	const batch = 64
+
+	for {
+		for i := 0; i < batch; i++ {
+			res, err := attempt(opts.Type, r)
+			if err != nil {
+				return nil, err
+			}
+			if Match(res.Address, opts.Prefix, opts.Suffix) {
+				atomic.AddUint64(attempts, uint64(i+1))
+				return res, nil
+			}
+		}
+		atomic.AddUint64(attempts, batch)
+
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		default:
+		}
+	}
+}
+
+// attempt draws a single address of the given type.
+func attempt(typ string, r io.Reader) (*Result, error) {
+	if typ == Actor {
+		seed := make([]byte, SeedBytes)
+		if _, err := io.ReadFull(r, seed); err != nil {
+			return nil, err
+		}
+		a, err := address.NewActorAddress(seed)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Type: typ, Address: a, Seed: seed}, nil
+	}
+
+	priv, err := keys.Generate(typ, r)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := keys.PublicKey(typ, priv)
+	if err != nil {
+		return nil, err
+	}
+	a, err := keys.Address(typ, pub)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Type: typ, Address: a, PrivateKey: priv, PublicKey: pub}, nil
+}
+
+// Match reports whether the base32 part of a, after its network and
+// protocol prefix, starts with prefix and ends with suffix.
+func Match(a address.Address, prefix, suffix string) bool {
+	s := a.String()
+	if len(s) < 2 {
+		return false
+	}
+	s = s[2:]
+	return strings.HasPrefix(s, prefix) && strings.HasSuffix(s, suffix)
+}
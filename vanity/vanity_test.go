@@ -0,0 +1,102 @@
+package vanity_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-address/keys"
+	"github.com/filecoin-project/go-address/vanity"
+)
+
+func TestExpectedAttempts(t *testing.T) {
+	testCases := []struct {
+		typ, prefix, suffix string
+		expected            float64
+	}{
+		{vanity.Secp256k1, "a", "", 32},
+		{vanity.Actor, "abc", "", 32 * 32 * 32},
+		{vanity.Secp256k1, "a", "y", 32 * 4},
+		// The last character only holds 2 bits of the checksum.
+		{vanity.Secp256k1, "", "ay", 32 * 4},
+		// The first character of BLS addresses only holds the sign bit and
+		// the top bits of the public key.
+		{vanity.BLS, "q", "", 8},
+		{vanity.BLS, "rx", "", 8 * 32},
+		{vanity.BLS, "", "q", 2},
+	}
+
+	for _, tc := range testCases {
+		expected, err := vanity.ExpectedAttempts(tc.typ, tc.prefix, tc.suffix)
+		require.NoError(t, err, tc)
+		require.Equal(t, tc.expected, expected, tc)
+	}
+
+	invalid := []struct {
+		typ, prefix, suffix string
+	}{
+		{vanity.Secp256k1, "", ""},
+		{vanity.Secp256k1, "a1", ""},
+		{vanity.Secp256k1, "A", ""},
+		{vanity.Secp256k1, "", "b"},
+		{vanity.BLS, "a", ""},
+		{vanity.BLS, "", "i"},
+		{vanity.Actor, strings.Repeat("a", 40), ""},
+		{vanity.Actor, strings.Repeat("a", 39), strings.Repeat("b", 2)},
+	}
+	for _, tc := range invalid {
+		_, err := vanity.ExpectedAttempts(tc.typ, tc.prefix, tc.suffix)
+		require.ErrorIs(t, err, vanity.ErrInvalidPattern, tc)
+	}
+
+	_, err := vanity.ExpectedAttempts("ed25519", "a", "")
+	require.ErrorIs(t, err, vanity.ErrUnknownType)
+}
+
+func TestSearch(t *testing.T) {
+	testCases := []vanity.Options{
+		{Type: vanity.Secp256k1, Prefix: "f"},
+		{Type: vanity.Actor, Prefix: "ab", Suffix: "a"},
+		{Type: vanity.BLS, Prefix: "r", Workers: 1},
+	}
+
+	for _, opts := range testCases {
+		res, err := vanity.Search(context.Background(), opts)
+		require.NoError(t, err, opts)
+		require.True(t, vanity.Match(res.Address, opts.Prefix, opts.Suffix), res.Address)
+		require.NotZero(t, res.Attempts)
+
+		// The result can be derived again from its secret.
+		var a address.Address
+		if opts.Type == vanity.Actor {
+			require.Len(t, res.Seed, vanity.SeedBytes)
+			a, err = address.NewActorAddress(res.Seed)
+		} else {
+			a, err = keys.KeyInfo{Type: res.Type, PrivateKey: res.PrivateKey}.Address()
+		}
+		require.NoError(t, err)
+		require.Equal(t, res.Address, a)
+	}
+}
+
+func TestSearchCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var reports int
+	_, err := vanity.Search(ctx, vanity.Options{
+		Type:             vanity.Actor,
+		Prefix:           "aaaaaaaaaaaaaaaaaaaa",
+		ProgressInterval: 10 * time.Millisecond,
+		Progress: func(p vanity.Progress) {
+			reports++
+			require.Equal(t, float64(1<<50)*float64(1<<50), p.Expected)
+		},
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NotZero(t, reports)
+}